@@ -12,4 +12,11 @@ var (
 	brown  = color.RGBA{165, 42, 42, 255}
 	black  = color.RGBA{0, 0, 0, 255}
 	white  = color.RGBA{255, 255, 255, 255}
+	water  = color.RGBA{40, 120, 255, 255}
+	oil    = color.RGBA{160, 130, 20, 255}
+	// faded is for lines that are there but don't stop anything; it's premultiplied, a quarter
+	// opaque purple.
+	faded = color.RGBA{64, 0, 64, 64}
+	// sleepy tints sleeping bodies in debug mode; it's premultiplied, half transparent blue.
+	sleepy = color.RGBA{0, 0, 128, 128}
 )
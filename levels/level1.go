@@ -4,73 +4,126 @@ import (
 	"fmt"
 	"image/color"
 	"log"
+	"math"
 	"math/rand"
 	"os"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/ssoroka/bounce/world"
 )
 
 const (
 	fps       = 60
 	itemCount = 5
-	FPSDelta  = 1 / float32(fps)
+	// maxSpawnSpeed is the largest random starting speed along each axis, in pixels per second.
+	maxSpawnSpeed = 60
 )
 
 type GameOptions struct {
 	Fullscreen bool
 }
 
+// Game adapts a world.World to ebiten: it renders the objects and turns input into edits.
 type Game struct {
-	Window         Size
-	WindowPosition Point
-	Objects        []Drawable
+	*world.World
+	Window         world.Size
+	WindowPosition world.Point
 	LastTick       time.Time
 	Options        GameOptions
+	// killed are the objects kill zones caught during the last Advance, removed once it's done.
+	killed []world.Object
+	sparks []spark
 }
 
-type Drawable interface {
-	Draw(s *ebiten.Image)
-	Update(delta float32) error
+// spark is a flash where something hit hard, fading out over sparkLife seconds.
+type spark struct {
+	at  world.Vector
+	age float32
+}
+
+const (
+	sparkLife = 0.3
+	// sparkSpeed is how much a hit has to change a body's speed, in pixels per second, to spark.
+	sparkSpeed = 300
+)
+
+// sparkOnImpact subscribes to the world's contacts and flashes wherever something hit hard.
+func (g *Game) sparkOnImpact() {
+	g.Events.BeginContact.Subscribe(func(e world.ContactEvent) {
+		r, ok := e.B.(world.Rigid)
+		if !ok || r.RigidBody().Mass == 0 {
+			return
+		}
+		if e.Impulse/r.RigidBody().Mass > sparkSpeed {
+			g.sparks = append(g.sparks, spark{at: e.Point})
+		}
+	})
 }
 
 var velocity = float32(0.0)
 
 func (g *Game) Update() (err error) {
 	deltaDur := time.Since(g.LastTick)
-	// delta := float32(deltaDur.Seconds())
-	delta := FPSDelta
+	delta := float32(deltaDur.Seconds())
 	velocity = float32(0.0)
 
+	g.CheckKeyboardInput()
+	if err = g.Advance(delta); err != nil {
+		return err
+	}
+	g.removeKilled()
+	for i := range g.sparks {
+		g.sparks[i].age += delta
+	}
+	g.sparks = slices.DeleteFunc(g.sparks, func(s spark) bool { return s.age > sparkLife })
+
+	step := g.TimeStep / float32(max(g.Substeps, 1))
 	for _, o := range g.Objects {
-		if err = o.Update(delta); err != nil {
-			return err
-		}
-		if o, ok := o.(*Circle); ok {
-			velocity += o.Velocity.Length()
+		if o, ok := o.(*world.Circle); ok {
+			speed := o.Velocity.Length()
+			velocity += speed
+			// the walls are thin lines, so balls that travel further than their radius in a step
+			// could slip through; only they pay for sweeping. It's never cleared again, so a ball
+			// loaded or made a bullet on purpose keeps it.
+			if speed*step > o.Radius {
+				o.Bullet = true
+			}
 		}
 	}
 
-	g.CheckKeyboardInput()
-	g.ApplyGravity()
-	g.CheckCollisions()
-
 	g.LastTick = g.LastTick.Add(deltaDur)
 	return nil
 }
 
-var (
-	debug          = false
-	gravity        = false
-	collisionCount = 0
-)
+var debug = false
 
 func (g *Game) Draw(screen *ebiten.Image) {
-	for _, c := range g.Objects {
-		c.Draw(screen)
+	for _, o := range g.Objects {
+		drawObject(screen, o)
+	}
+	for _, j := range g.Joints {
+		drawJoint(screen, j)
+	}
+	for _, s := range g.sparks {
+		drawSpark(screen, s)
+	}
+
+	if len(polygonPoints) > 0 {
+		x, y := ebiten.CursorPosition()
+		cursor := world.Point{X: float32(x), Y: float32(y)}
+		for i, p := range polygonPoints {
+			next := cursor
+			if i+1 < len(polygonPoints) {
+				next = polygonPoints[i+1]
+			}
+			vector.StrokeLine(screen, p.X, p.Y, next.X, next.Y, 2, purple, false)
+		}
 	}
 
 	if drawing {
@@ -78,14 +131,33 @@ func (g *Game) Draw(screen *ebiten.Image) {
 		case DrawObjectBoundary:
 			vector.StrokeLine(screen, drawStart.X, drawStart.Y, drawEnd.X, drawEnd.Y, 2, purple, false)
 		case DrawObjectCube:
-			size := Point{X: drawEnd.X - drawStart.X, Y: drawEnd.Y - drawStart.Y}
-			pos := Point{X: drawStart.X, Y: drawStart.Y}
-			c := NewCube(pos.X, pos.Y, size.X, size.Y, color.RGBA{R: uint8(rand.Intn(256)), G: uint8(rand.Intn(256)), B: uint8(rand.Intn(256)), A: 255}, Vector{0, 0})
-			c.Draw(screen)
+			size := world.Point{X: drawEnd.X - drawStart.X, Y: drawEnd.Y - drawStart.Y}
+			pos := world.Point{X: drawStart.X, Y: drawStart.Y}
+			c := world.NewCube(pos.X, pos.Y, size.X, size.Y, color.RGBA{R: uint8(rand.Intn(256)), G: uint8(rand.Intn(256)), B: uint8(rand.Intn(256)), A: 255}, world.Vector{})
+			drawCube(screen, c)
 		case DrawObjectCircle:
-			radius := Vector{X: drawEnd.X - drawStart.X, Y: drawEnd.Y - drawStart.Y}.Length()
-			c := NewCircle(drawStart.X, drawStart.Y, radius, color.RGBA{R: uint8(rand.Intn(256)), G: uint8(rand.Intn(256)), B: uint8(rand.Intn(256)), A: 255}, Vector{0, 0})
-			c.Draw(screen)
+			radius := world.Vector{X: drawEnd.X - drawStart.X, Y: drawEnd.Y - drawStart.Y}.Length()
+			c := world.NewCircle(drawStart.X, drawStart.Y, radius, color.RGBA{R: uint8(rand.Intn(256)), G: uint8(rand.Intn(256)), B: uint8(rand.Intn(256)), A: 255}, world.Vector{})
+			drawCircle(screen, c)
+		case DrawObjectBox:
+			b := world.NewBox(min(drawStart.X, drawEnd.X), min(drawStart.Y, drawEnd.Y), abs(drawEnd.X-drawStart.X), abs(drawEnd.Y-drawStart.Y), randomColor(), world.Vector{})
+			drawBox(screen, b)
+		case DrawObjectSoftBody:
+			drawSoftBody(screen, newSoftBody(world.Vector{}))
+		case DrawObjectCloth:
+			drawCloth(screen, newCloth())
+		case DrawObjectRope:
+			drawCloth(screen, newRope())
+		case DrawObjectKillZone:
+			drawSensor(screen, newKillZone())
+		case DrawObjectWind, DrawObjectAttractor, DrawObjectVortex:
+			drawForceField(screen, newForceField())
+		case DrawObjectFluid:
+			drawFluid(screen, newFluid())
+		case DrawObjectLiquid:
+			vector.StrokeRect(screen, min(drawStart.X, drawEnd.X), min(drawStart.Y, drawEnd.Y), abs(drawEnd.X-drawStart.X), abs(drawEnd.Y-drawStart.Y), 1, water, false)
+		case DrawObjectKinematic:
+			drawKinematic(screen, newKinematic())
 		}
 	}
 
@@ -94,8 +166,11 @@ func (g *Game) Draw(screen *ebiten.Image) {
 Velocity: %.2f
 Count: %d
 Collisions: %d
+Gravity: %s
+Sleeping: %d
+Filter: %s
 Velocity Init: %t
-Current Draw Object %s`, ebiten.ActualFPS(), velocity, len(g.Objects)-1, collisionCount, initWithVelocity, currentDrawObject.String()))
+Current Draw Object %s`, ebiten.ActualFPS(), velocity, len(g.Objects)-1, g.CollisionCount, g.gravity(), g.sleeping(), g.hoverFilter(), initWithVelocity, currentDrawObject.String()))
 	}
 
 	if recording && ffmpegPipe != nil {
@@ -110,103 +185,24 @@ func (g *Game) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeigh
 	return int(g.Window.W), int(g.Window.H)
 }
 
-func (g *Game) CheckCollisions() {
-	for i, o1 := range g.Objects {
-		for j, o2 := range g.Objects {
-			if i >= j {
-				continue
-			}
-			if col := CheckCollision(o1, o2); col.Hit {
-				collisionCount++
-				// d := col.Depth
-				// d := float32(1.0)
-				if _, ok := o2.(*CubeBoundary); ok {
-					o1, o2 = o2, o1
-					col.Depth = -col.Depth
-				}
-				if _, ok := o2.(*Boundary); ok {
-					o1, o2 = o2, o1
-					col.Depth = -col.Depth
-				}
-				// if _, ok := o1.(*Cube); ok {
-				// 	if _, ok := o2.(*Circle); ok {
-				// 		o1, o2 = o2, o1
-				// 		col.Depth = -col.Depth
-				// 	}
-				// }
-				// if one is a boundary, push the other out
-				if _, ok := o1.(*CubeBoundary); ok {
-					if c, ok := o2.(*Circle); ok {
-						// push o2
-						c.Velocity = c.Velocity.Reflect(col.Normal)
-
-						// Push circle out of wall (adjust position, not velocity)
-						c.X += col.Normal.X * col.Depth
-						c.Y += col.Normal.Y * col.Depth
-					}
-					// if c, ok := o2.(*Cube); ok {
-					// 	// push o2
-					// 	c.Velocity = c.Velocity.Reflect(col.Normal)
-
-					// 	// Push cube out of wall (adjust position, not velocity)
-					// 	c.X += col.Normal.X * col.Depth
-					// 	c.Y += col.Normal.Y * col.Depth
-					// }
-				} else if _, ok := o1.(*Boundary); ok {
-					if c, ok := o2.(*Circle); ok {
-						fmt.Println("wall collision!")
-						// push o2
-						c.Velocity = c.Velocity.Reflect(col.Normal)
-
-						// Push circle out of wall (adjust position, not velocity)
-						c.X += col.Normal.X * col.Depth
-						c.Y += col.Normal.Y * col.Depth
-					}
-				} else if c1, ok := o1.(*Circle); ok {
-					if c2, ok := o2.(*Circle); ok {
-						// two circles
-						// c1 := o1.(*Circle)
-						// c2 := o2.(*Circle)
-
-						// Separate circles
-						c1.X -= col.Normal.X * col.Depth / 2
-						c1.Y -= col.Normal.Y * col.Depth / 2
-						c2.X += col.Normal.X * col.Depth / 2
-						c2.Y += col.Normal.Y * col.Depth / 2
-
-						// Elastic collision (equal mass)
-						// Swap velocity components along collision normal
-						relVel := Vector{c1.Velocity.X - c2.Velocity.X, c1.Velocity.Y - c2.Velocity.Y}
-						dot := relVel.X*col.Normal.X + relVel.Y*col.Normal.Y
-
-						c1.Velocity.X -= dot * col.Normal.X
-						c1.Velocity.Y -= dot * col.Normal.Y
-						c2.Velocity.X += dot * col.Normal.X
-						c2.Velocity.Y += dot * col.Normal.Y
-					}
-					// if c2, ok := o2.(*Cube); ok {
-					// 	// circle and cube
-					// 	_ = c1
-					// 	_ = c2
-					// }
-					// } else if c1, ok := o1.(*Cube); ok {
-					// 	if c2, ok := o2.(*Cube); ok {
-					// 		// two cubes
-					// 		_ = c1
-					// 		_ = c2
-					// 	}
-				}
-			}
-		}
-	}
-}
-
 type DrawObjectType int
 
 const (
 	DrawObjectBoundary DrawObjectType = iota
 	DrawObjectCube
 	DrawObjectCircle
+	DrawObjectBox
+	DrawObjectPolygon
+	DrawObjectSoftBody
+	DrawObjectCloth
+	DrawObjectRope
+	DrawObjectKillZone
+	DrawObjectWind
+	DrawObjectAttractor
+	DrawObjectVortex
+	DrawObjectFluid
+	DrawObjectLiquid
+	DrawObjectKinematic
 )
 
 func (t DrawObjectType) String() string {
@@ -217,6 +213,30 @@ func (t DrawObjectType) String() string {
 		return "Cube"
 	case DrawObjectCircle:
 		return "Circle"
+	case DrawObjectBox:
+		return "Box"
+	case DrawObjectPolygon:
+		return "Polygon"
+	case DrawObjectSoftBody:
+		return "SoftBody"
+	case DrawObjectCloth:
+		return "Cloth"
+	case DrawObjectRope:
+		return "Rope"
+	case DrawObjectKillZone:
+		return "KillZone"
+	case DrawObjectWind:
+		return "Wind"
+	case DrawObjectAttractor:
+		return "Attractor"
+	case DrawObjectVortex:
+		return "Vortex"
+	case DrawObjectFluid:
+		return "Fluid"
+	case DrawObjectLiquid:
+		return "Liquid"
+	case DrawObjectKinematic:
+		return "Kinematic"
 	default:
 		return "Unknown"
 	}
@@ -224,45 +244,61 @@ func (t DrawObjectType) String() string {
 
 var (
 	drawing           = false
-	drawStart         Point
-	drawEnd           Point
+	drawStart         world.Point
+	drawEnd           world.Point
 	currentDrawObject = DrawObjectBoundary
 	initWithVelocity  = true
 )
 
 func (g *Game) CheckKeyboardInput() {
 	if inpututil.IsKeyJustPressed(ebiten.KeyG) {
-		gravity = !gravity
+		// cycle from uniform gravity to circles pulling on each other to none at all
+		switch {
+		case g.NBody:
+			g.NBody, g.Gravity = false, false
+		case g.Gravity:
+			g.NBody = true
+		default:
+			g.Gravity = true
+		}
+		g.WakeAll()
 	}
 	if ebiten.IsKeyPressed(ebiten.KeyQ) {
 		os.Exit(0)
 	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyI) {
+		g.toggleContainer()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyU) {
+		g.changeLineAtCursor()
+	}
+	// the arrows squeeze, stretch and turn the container for as long as they're held
+	cube.Growth, cube.AngularVelocity = 0, 0
 	if ebiten.IsKeyPressed(ebiten.KeyArrowDown) {
-		cube.Scale(0.999)
-		// cube.Size = cube.Size.Scale(0.99)
-		// cube.RecalculateCorners()
+		cube.Growth -= containerGrowth
 	}
 	if ebiten.IsKeyPressed(ebiten.KeyArrowUp) {
-		cube.Scale(1.001)
-		// cube.Size = cube.Size.Scale(1.01)
-		// cube.RecalculateCorners()
+		cube.Growth += containerGrowth
 	}
 	if ebiten.IsKeyPressed(ebiten.KeyArrowLeft) {
-		cube.Rotation -= 0.001
-		cube.RecalculateCorners()
+		cube.AngularVelocity -= containerSpin
 	}
 	if ebiten.IsKeyPressed(ebiten.KeyArrowRight) {
-		cube.Rotation += 0.001
-		cube.RecalculateCorners()
+		cube.AngularVelocity += containerSpin
 	}
 	if inpututil.IsKeyJustPressed(ebiten.KeyZ) || (ebiten.IsKeyPressed(ebiten.KeyZ) && ebiten.IsKeyPressed(ebiten.KeyShift)) {
 		for i, obj := range g.Objects {
 			switch obj.(type) {
-			case *Boundary, *CubeBoundary:
+			case *world.Boundary, *world.CubeBoundary:
 				continue
 			}
-			// delete this item
+			// delete this item, and any joints holding it
 			g.Objects = append(g.Objects[:i], g.Objects[i+1:]...)
+			if r, ok := obj.(world.Rigid); ok {
+				g.removeJoints(r.RigidBody())
+			}
+			// whatever was resting on it has to fall
+			g.WakeAll()
 			break
 		}
 	}
@@ -272,6 +308,12 @@ func (g *Game) CheckKeyboardInput() {
 	if inpututil.IsKeyJustPressed(ebiten.KeyC) || (ebiten.IsKeyPressed(ebiten.KeyC) && ebiten.IsKeyPressed(ebiten.KeyShift)) {
 		createCircle(g)
 	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyP) || (ebiten.IsKeyPressed(ebiten.KeyP) && ebiten.IsKeyPressed(ebiten.KeyShift)) {
+		createPolygon(g)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyJ) {
+		g.pinAtCursor()
+	}
 	if inpututil.IsKeyJustPressed(ebiten.KeyD) {
 		debug = !debug
 	}
@@ -297,6 +339,8 @@ func (g *Game) CheckKeyboardInput() {
 		if err := g.LoadState("save.json"); err != nil {
 			log.Println("error loading state:", err)
 		} else {
+			g.armKillZones()
+			g.findWalls()
 			log.Println("state loaded from save.json")
 		}
 	}
@@ -311,6 +355,51 @@ func (g *Game) CheckKeyboardInput() {
 	if inpututil.IsKeyJustPressed(ebiten.KeyM) {
 		currentDrawObject = DrawObjectCircle
 	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyComma) {
+		currentDrawObject = DrawObjectBox
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyPeriod) {
+		currentDrawObject = DrawObjectPolygon
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeySlash) {
+		currentDrawObject = DrawObjectSoftBody
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeySemicolon) {
+		currentDrawObject = DrawObjectCloth
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyQuote) {
+		currentDrawObject = DrawObjectRope
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyK) {
+		currentDrawObject = DrawObjectKillZone
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyW) {
+		currentDrawObject = DrawObjectWind
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyA) {
+		currentDrawObject = DrawObjectAttractor
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyO) {
+		currentDrawObject = DrawObjectVortex
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyH) {
+		currentDrawObject = DrawObjectFluid
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyT) {
+		currentDrawObject = DrawObjectLiquid
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyE) {
+		currentDrawObject = DrawObjectKinematic
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyY) {
+		metaballs = !metaballs
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyBackspace) {
+		g.removeFieldAtCursor()
+	}
+	if _, dy := ebiten.Wheel(); dy != 0 {
+		g.scaleFieldAtCursor(dy)
+	}
 	if inpututil.IsKeyJustPressed(ebiten.KeyV) {
 		initWithVelocity = !initWithVelocity
 	}
@@ -319,56 +408,486 @@ func (g *Game) CheckKeyboardInput() {
 		ebiten.SetFullscreen(g.Options.Fullscreen)
 	}
 
+	if currentDrawObject == DrawObjectPolygon {
+		g.CheckPolygonInput()
+		return
+	}
+	polygonPoints = nil
+
 	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
 		if drawing {
 			return
 		}
 		drawing = true
 		x, y := ebiten.CursorPosition()
-		drawStart = Point{X: float32(x), Y: float32(y)}
+		drawStart = world.Point{X: float32(x), Y: float32(y)}
 	}
 	if drawing && inpututil.IsMouseButtonJustReleased(ebiten.MouseButtonLeft) {
 		drawing = false
 		x, y := ebiten.CursorPosition()
-		drawEnd = Point{X: float32(x), Y: float32(y)}
+		drawEnd = world.Point{X: float32(x), Y: float32(y)}
 		switch currentDrawObject {
 		case DrawObjectBoundary:
-			g.Objects = append(g.Objects, NewBoundaryLine(drawStart, drawEnd, 2, purple))
+			g.Objects = append(g.Objects, world.NewBoundaryLine(drawStart, drawEnd, 2, purple))
 		case DrawObjectCube:
-			size := Point{X: drawEnd.X - drawStart.X, Y: drawEnd.Y - drawStart.Y}
-			pos := Point{X: drawStart.X, Y: drawStart.Y}
-			var velocity Vector
+			size := world.Point{X: drawEnd.X - drawStart.X, Y: drawEnd.Y - drawStart.Y}
+			pos := world.Point{X: drawStart.X, Y: drawStart.Y}
+			var velocity world.Vector
 			if initWithVelocity {
-				velocity = Vector{rand.Float32()*2 - 1, rand.Float32()*2 - 1}
+				velocity = randomVelocity()
 			}
-			c := NewCube(pos.X, pos.Y, size.X, size.Y, color.RGBA{R: uint8(rand.Intn(256)), G: uint8(rand.Intn(256)), B: uint8(rand.Intn(256)), A: 255}, velocity)
+			c := world.NewCube(pos.X, pos.Y, size.X, size.Y, color.RGBA{R: uint8(rand.Intn(256)), G: uint8(rand.Intn(256)), B: uint8(rand.Intn(256)), A: 255}, velocity)
 			g.Objects = append(g.Objects, c)
 		case DrawObjectCircle:
-			radius := Vector{X: drawEnd.X - drawStart.X, Y: drawEnd.Y - drawStart.Y}.Length()
-			var velocity Vector
+			radius := world.Vector{X: drawEnd.X - drawStart.X, Y: drawEnd.Y - drawStart.Y}.Length()
+			var velocity world.Vector
 			if initWithVelocity {
-				velocity = Vector{rand.Float32()*2 - 1, rand.Float32()*2 - 1}
+				velocity = randomVelocity()
 			}
-			c := NewCircle(drawStart.X, drawStart.Y, radius, color.RGBA{R: uint8(rand.Intn(256)), G: uint8(rand.Intn(256)), B: uint8(rand.Intn(256)), A: 255}, velocity)
+			c := world.NewCircle(drawStart.X, drawStart.Y, radius, color.RGBA{R: uint8(rand.Intn(256)), G: uint8(rand.Intn(256)), B: uint8(rand.Intn(256)), A: 255}, velocity)
 			g.Objects = append(g.Objects, c)
+		case DrawObjectBox:
+			var velocity world.Vector
+			if initWithVelocity {
+				velocity = randomVelocity()
+			}
+			b := world.NewBox(min(drawStart.X, drawEnd.X), min(drawStart.Y, drawEnd.Y), abs(drawEnd.X-drawStart.X), abs(drawEnd.Y-drawStart.Y), randomColor(), velocity)
+			b.Filled = true
+			g.Objects = append(g.Objects, b)
+		case DrawObjectSoftBody:
+			var velocity world.Vector
+			if initWithVelocity {
+				velocity = randomVelocity()
+			}
+			g.Objects = append(g.Objects, newSoftBody(velocity))
+		case DrawObjectCloth:
+			g.Objects = append(g.Objects, newCloth())
+		case DrawObjectRope:
+			g.Objects = append(g.Objects, newRope())
+		case DrawObjectKillZone:
+			g.Objects = append(g.Objects, newKillZone())
+			g.armKillZones()
+		case DrawObjectWind, DrawObjectAttractor, DrawObjectVortex:
+			g.Objects = append(g.Objects, newForceField())
+			// whatever has settled in the field has to feel it
+			g.WakeAll()
+		case DrawObjectFluid:
+			g.Objects = append(g.Objects, newFluid())
+			g.WakeAll()
+		case DrawObjectLiquid:
+			g.pour()
+		case DrawObjectKinematic:
+			g.Objects = append(g.Objects, newKinematic())
 		}
 	} else if drawing {
 		x, y := ebiten.CursorPosition()
-		drawEnd = Point{X: float32(x), Y: float32(y)}
+		drawEnd = world.Point{X: float32(x), Y: float32(y)}
+	}
+}
+
+// newSoftBody makes a blob centered on drawStart that reaches out to drawEnd.
+func newSoftBody(velocity world.Vector) *world.SoftBody {
+	radius := world.Vector{X: drawEnd.X - drawStart.X, Y: drawEnd.Y - drawStart.Y}.Length()
+	nodes := min(max(int(radius/4), 8), 32)
+	s := world.NewSoftBody(drawStart.X, drawStart.Y, radius, nodes, false, randomColor(), velocity)
+	s.Filled = true
+	return s
+}
+
+// clothSpacing is the distance between the nodes of drawn cloth and rope.
+const clothSpacing = 12
+
+// newCloth makes a sheet filling the rectangle dragged out from drawStart to drawEnd, hung from
+// every third node along its top.
+func newCloth() *world.Cloth {
+	cols := int(abs(drawEnd.X-drawStart.X)/clothSpacing) + 1
+	rows := int(abs(drawEnd.Y-drawStart.Y)/clothSpacing) + 1
+	c := world.NewCloth(min(drawStart.X, drawEnd.X), min(drawStart.Y, drawEnd.Y), cols, rows, clothSpacing, randomColor())
+	for i := 0; i < cols; i += 3 {
+		c.Pin(i, c.Nodes[i].Point)
+	}
+	if cols%3 != 1 {
+		c.Pin(cols-1, c.Nodes[cols-1].Point)
+	}
+	c.Filled = true
+	return c
+}
+
+// newRope makes a rope hanging from drawStart that reaches to drawEnd.
+func newRope() *world.Cloth {
+	length := world.Vector{X: drawEnd.X - drawStart.X, Y: drawEnd.Y - drawStart.Y}.Length()
+	return world.NewRope(drawStart, drawEnd, int(length/clothSpacing)+1, randomColor())
+}
+
+// killZoneName names the sensors that remove whatever falls into them.
+const killZoneName = "kill zone"
+
+// newKillZone makes a kill zone over the rectangle dragged out from drawStart to drawEnd.
+func newKillZone() *world.Sensor {
+	s := world.NewRectSensor(min(drawStart.X, drawEnd.X), min(drawStart.Y, drawEnd.Y), abs(drawEnd.X-drawStart.X), abs(drawEnd.Y-drawStart.Y), red)
+	s.Name = killZoneName
+	return s
+}
+
+const (
+	windStrength      = 600
+	attractorStrength = 12000
+	vortexStrength    = 1500
+	// fieldScrollScale is how much one notch of the mouse wheel strengthens or weakens a field.
+	fieldScrollScale = 1.1
+)
+
+// newForceField makes a field of the current kind from the drag: wind fills the rectangle dragged
+// out from drawStart to drawEnd and blows along the drag, while attractors and vortices are
+// centered on drawStart and reach out to drawEnd. Holding shift turns an attractor into a
+// repulsor.
+func newForceField() *world.ForceField {
+	drag := world.Vector{X: drawEnd.X - drawStart.X, Y: drawEnd.Y - drawStart.Y}
+	switch currentDrawObject {
+	case DrawObjectAttractor:
+		strength := float32(attractorStrength)
+		if ebiten.IsKeyPressed(ebiten.KeyShift) {
+			strength = -strength
+		}
+		return world.NewAttractor(drawStart, drag.Length(), strength, orange)
+	case DrawObjectVortex:
+		return world.NewVortex(drawStart, drag.Length(), vortexStrength, purple)
+	}
+	region := world.AABB{
+		Min: world.Point{X: min(drawStart.X, drawEnd.X), Y: min(drawStart.Y, drawEnd.Y)},
+		Max: world.Point{X: max(drawStart.X, drawEnd.X), Y: max(drawStart.Y, drawEnd.Y)},
+	}
+	if drag == (world.Vector{}) {
+		drag.X = 1
+	}
+	return world.NewWind(region, drag, windStrength, blue)
+}
+
+// newFluid makes a pool of water over the rectangle dragged out from drawStart to drawEnd, or of
+// oil while shift is held.
+func newFluid() *world.Fluid {
+	x, y := min(drawStart.X, drawEnd.X), min(drawStart.Y, drawEnd.Y)
+	w, h := abs(drawEnd.X-drawStart.X), abs(drawEnd.Y-drawStart.Y)
+	if ebiten.IsKeyPressed(ebiten.KeyShift) {
+		return world.NewOil(x, y, w, h, oil)
+	}
+	return world.NewWater(x, y, w, h, water)
+}
+
+const (
+	platformWidth  = 100
+	platformHeight = 12
+	platformSpeed  = 80
+	platformPause  = 1
+	spinnerSpeed   = 2
+)
+
+// newKinematic makes a platform that shuttles between drawStart and drawEnd, pausing at each end,
+// or while shift is held a bar centered on drawStart and reaching out to drawEnd that spins.
+func newKinematic() *world.Kinematic {
+	if ebiten.IsKeyPressed(ebiten.KeyShift) {
+		length := max(world.Vector{X: drawEnd.X - drawStart.X, Y: drawEnd.Y - drawStart.Y}.Length(), platformHeight)
+		bar := world.NewBox(drawStart.X-length, drawStart.Y-platformHeight/2, 2*length, platformHeight, randomColor(), world.Vector{})
+		bar.Filled = true
+		return world.NewKinematic(bar, world.Spin{Pivot: drawStart, AngularVelocity: spinnerSpeed})
+	}
+	platform := world.NewBox(drawStart.X-platformWidth/2, drawStart.Y-platformHeight/2, platformWidth, platformHeight, randomColor(), world.Vector{})
+	platform.Filled = true
+	return world.NewKinematic(platform, world.Waypoints{
+		Points: []world.Point{drawStart, drawEnd},
+		Speed:  platformSpeed,
+		Pause:  platformPause,
+	})
+}
+
+// pour fills the rectangle dragged out from drawStart to drawEnd with liquid. It all goes into the
+// one liquid, so it mixes with what was poured before.
+func (g *Game) pour() {
+	var l *world.Liquid
+	for _, o := range g.Objects {
+		if found, ok := o.(*world.Liquid); ok {
+			l = found
+			break
+		}
+	}
+	if l == nil {
+		l = world.NewLiquid(water)
+		g.Objects = append(g.Objects, l)
+	}
+	var velocity world.Vector
+	if initWithVelocity {
+		velocity = randomVelocity()
+	}
+	l.Pour(min(drawStart.X, drawEnd.X), min(drawStart.Y, drawEnd.Y), abs(drawEnd.X-drawStart.X), abs(drawEnd.Y-drawStart.Y), velocity)
+}
+
+// lineReach is how close, in pixels, the cursor has to be to a boundary line to pick it.
+const lineReach = 10
+
+// lineAtCursor returns the boundary line closest to the cursor, if one is within reach.
+func (g *Game) lineAtCursor() *world.Line {
+	x, y := ebiten.CursorPosition()
+	cursor := world.Vector{X: float32(x), Y: float32(y)}
+	var closest *world.Line
+	reach := float32(lineReach)
+	for _, o := range g.Objects {
+		b, ok := o.(*world.Boundary)
+		if !ok {
+			continue
+		}
+		for i := range b.Lines {
+			if d := cursor.Sub(b.Lines[i].ClosestPoint(cursor)).Length(); d < reach {
+				closest, reach = &b.Lines[i], d
+			}
+		}
+	}
+	return closest
+}
+
+// changeLineAtCursor cycles the line under the cursor from two-sided to one-sided to
+// pass-through, or with shift held flips which side a one-sided line is solid from.
+func (g *Game) changeLineAtCursor() {
+	line := g.lineAtCursor()
+	if line == nil {
+		return
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyShift) {
+		*line = line.Flipped()
+	} else {
+		line.Sides = (line.Sides + 1) % (world.PassThrough + 1)
+	}
+	log.Printf("line is now %s", line.Sides)
+	// whatever was resting on it may have to fall
+	g.WakeAll()
+}
+
+// fieldAtCursor returns the topmost force field reaching the cursor.
+func (g *Game) fieldAtCursor() (int, *world.ForceField) {
+	x, y := ebiten.CursorPosition()
+	cursor := world.Point{X: float32(x), Y: float32(y)}
+	for i := len(g.Objects) - 1; i >= 0; i-- {
+		if f, ok := g.Objects[i].(*world.ForceField); ok && f.Contains(cursor) {
+			return i, f
+		}
+	}
+	return -1, nil
+}
+
+// scaleFieldAtCursor strengthens the field under the cursor for each notch scrolled up, and
+// weakens it for each notch down.
+func (g *Game) scaleFieldAtCursor(notches float64) {
+	if _, f := g.fieldAtCursor(); f != nil {
+		f.Strength *= float32(math.Pow(fieldScrollScale, notches))
+		g.WakeAll()
+	}
+}
+
+// removeFieldAtCursor deletes the field under the cursor.
+func (g *Game) removeFieldAtCursor() {
+	if i, f := g.fieldAtCursor(); f != nil {
+		g.Objects = slices.Delete(g.Objects, i, i+1)
+		g.WakeAll()
+	}
+}
+
+// armKillZones hooks up every kill zone in the world; callbacks aren't saved, so this is needed
+// after loading too.
+func (g *Game) armKillZones() {
+	for _, o := range g.Objects {
+		if s, ok := o.(*world.Sensor); ok && s.Name == killZoneName {
+			s.OnEnter = func(_ *world.Sensor, o world.Object) {
+				g.killed = append(g.killed, o)
+			}
+		}
+	}
+}
+
+// removeKilled removes the objects caught by kill zones. The corners of a Cube and the nodes of
+// soft bodies and cloth take the whole object with them.
+func (g *Game) removeKilled() {
+	if len(g.killed) == 0 {
+		return
+	}
+	g.Objects = slices.DeleteFunc(g.Objects, func(o world.Object) bool {
+		if slices.Contains(g.killed, o) {
+			return true
+		}
+		if c, ok := o.(world.Composite); ok {
+			return slices.ContainsFunc(c.Parts(), func(p world.Object) bool { return slices.Contains(g.killed, p) })
+		}
+		return false
+	})
+	for _, o := range g.killed {
+		if r, ok := o.(world.Rigid); ok {
+			g.removeJoints(r.RigidBody())
+		}
+	}
+	g.killed = nil
+	g.WakeAll()
+}
+
+// polygonPoints are the vertices clicked so far in DrawObjectPolygon mode.
+var polygonPoints []world.Point
+
+// CheckPolygonInput builds a polygon one left click per vertex; a right click or Enter finishes
+// it and Escape starts over.
+func (g *Game) CheckPolygonInput() {
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		x, y := ebiten.CursorPosition()
+		polygonPoints = append(polygonPoints, world.Point{X: float32(x), Y: float32(y)})
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		polygonPoints = nil
+	}
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight) || inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+		var velocity world.Vector
+		if initWithVelocity {
+			velocity = randomVelocity()
+		}
+		if p := world.NewPolygon(polygonPoints, randomColor(), velocity); p != nil {
+			p.Filled = true
+			g.Objects = append(g.Objects, p)
+		}
+		polygonPoints = nil
+	}
+}
+
+// pinAtCursor pins the body under the mouse to the world where it was clicked, so it swings.
+func (g *Game) pinAtCursor() {
+	x, y := ebiten.CursorPosition()
+	cursor := world.Point{X: float32(x), Y: float32(y)}
+	if b := g.bodyAt(cursor); b != nil {
+		g.AddJoint(world.NewRevoluteJoint(nil, b, cursor))
+		g.WakeAll()
 	}
 }
 
+// bodyAt returns the topmost body whose bounds contain p.
+func (g *Game) bodyAt(p world.Point) *world.Body {
+	for i := len(g.Objects) - 1; i >= 0; i-- {
+		r, ok := g.Objects[i].(world.Rigid)
+		if !ok {
+			continue
+		}
+		if b, ok := r.(world.Bounded); ok && b.Bounds().Overlaps(world.AABB{Min: p, Max: p}) {
+			return r.RigidBody()
+		}
+	}
+	return nil
+}
+
+// hoverFilter describes the collision filter of the topmost object under the cursor.
+func (g *Game) hoverFilter() string {
+	x, y := ebiten.CursorPosition()
+	cursor := world.AABB{Min: world.Point{X: float32(x), Y: float32(y)}, Max: world.Point{X: float32(x), Y: float32(y)}}
+	for i := len(g.Objects) - 1; i >= 0; i-- {
+		o := g.Objects[i]
+		if b, ok := o.(world.Bounded); !ok || !b.Bounds().Overlaps(cursor) {
+			continue
+		}
+		// a composite shows the filter of its first part
+		f, ok := o.(world.Filtered)
+		if c, isComposite := o.(world.Composite); isComposite && len(c.Parts()) > 0 {
+			f, ok = c.Parts()[0].(world.Filtered)
+		}
+		if ok {
+			filter := f.CollisionFilter()
+			return fmt.Sprintf("%s category %#x mask %#x group %d", strings.TrimPrefix(fmt.Sprintf("%T", o), "*world."), filter.Category, filter.Mask, filter.Group)
+		}
+	}
+	return "none"
+}
+
+// gravity describes which gravity is on.
+func (g *Game) gravity() string {
+	switch {
+	case g.NBody:
+		return "n-body"
+	case g.Gravity:
+		return "uniform"
+	}
+	return "off"
+}
+
+// sleeping counts the objects that are asleep.
+func (g *Game) sleeping() int {
+	n := 0
+	for _, o := range g.Objects {
+		if world.Asleep(o) {
+			n++
+		}
+	}
+	return n
+}
+
+// removeJoints drops every joint attached to b.
+func (g *Game) removeJoints(b *world.Body) {
+	g.Joints = slices.DeleteFunc(g.Joints, func(j world.Joint) bool {
+		a, other := j.Bodies()
+		return a == b || other == b
+	})
+}
+
 var (
-	cube   *CubeBoundary
-	pixels []byte
+	// cube is the container the arrow keys move, and boundary the jagged walls; the I key swaps
+	// one for the other in the world.
+	cube     *world.CubeBoundary
+	boundary *world.Boundary
+	pixels   []byte
 )
 
+const (
+	// containerSpin is how fast the arrows turn the container, in radians per second, and
+	// containerGrowth how fast they grow or shrink it, as a fraction of its size per second.
+	containerSpin   = 1
+	containerGrowth = 0.2
+)
+
+// toggleContainer swaps the jagged walls for the container the arrow keys move, or back.
+func (g *Game) toggleContainer() {
+	for i, o := range g.Objects {
+		switch o {
+		case world.Object(boundary):
+			g.Objects[i] = cube
+		case world.Object(cube):
+			g.Objects[i] = boundary
+		default:
+			continue
+		}
+		g.WakeAll()
+		return
+	}
+}
+
+// findWalls picks up the loaded walls, so the arrow keys move a loaded container and the I key can
+// swap it back for loaded jagged walls. Lines drawn later are boundaries too, but the walls come
+// first.
+func (g *Game) findWalls() {
+	foundCube, foundBoundary := false, false
+	for _, o := range g.Objects {
+		switch o := o.(type) {
+		case *world.CubeBoundary:
+			if !foundCube {
+				cube, foundCube = o, true
+			}
+		case *world.Boundary:
+			if !foundBoundary {
+				boundary, foundBoundary = o, true
+			}
+		}
+	}
+}
+
 func Level1() {
 	windowW, windowH := ebiten.Monitor().Size()
 	g := &Game{
+		World:   world.New(),
 		Options: GameOptions{Fullscreen: true},
-		Window:  Size{W: float32(windowW), H: float32(windowH)},
+		Window:  world.Size{W: float32(windowW), H: float32(windowH)},
 	}
+	// soft bodies sink into the walls with only one contact solve per frame
+	g.Substeps = 4
+	g.sparkOnImpact()
 
 	// make a buffer for reading pixels when recording
 	pixels = make([]byte, int(g.Window.W)*int(g.Window.H)*4)
@@ -377,9 +896,8 @@ func Level1() {
 	// ebiten.SetVsyncEnabled(false)
 	ebiten.SetFullscreen(g.Options.Fullscreen)
 	ebiten.SetWindowSize(int(g.Window.W), int(g.Window.H))
-	cube = NewCubeBoundary(0, 0, g.Window.W-2, g.Window.H-2, 2, purple)
-	// g.Objects = append(g.Objects, cube)
-	boundary := NewBoundary(0, 0, g.Window.W-2, g.Window.H-2, 2, purple)
+	cube = world.NewCubeBoundary(0, 0, g.Window.W-2, g.Window.H-2, 2, purple)
+	boundary = world.NewBoundary(0, 0, g.Window.W-2, g.Window.H-2, 2, purple)
 	g.Objects = append(g.Objects, boundary)
 	g.Objects = append(g.Objects, createCube(g))
 	for range itemCount {
@@ -398,8 +916,9 @@ func createCircle(g *Game) {
 	x := rand.Float32() * (cube.W - size)
 	y := rand.Float32() * (cube.H - size)
 	color := color.RGBA{R: uint8(rand.Intn(256)), G: uint8(rand.Intn(256)), B: uint8(rand.Intn(256)), A: 255}
-	velocity := Vector{rand.Float32()*2 - 1, rand.Float32()*2 - 1}
-	g.Objects = append(g.Objects, NewCircle(x, y, size/2, color, velocity))
+	velocity := randomVelocity()
+	c := world.NewCircle(x, y, size/2, color, velocity)
+	g.Objects = append(g.Objects, c)
 }
 
 var keyStates = make(map[ebiten.Key]bool)
@@ -415,25 +934,36 @@ func isKeyJustPressed(key ebiten.Key) bool {
 	return false
 }
 
-func createCube(g *Game) *Cube {
+func createPolygon(g *Game) {
+	radius := rand.Float32()*20 + 10
+	x := rand.Float32()*(cube.W-2*radius) + radius
+	y := rand.Float32()*(cube.H-2*radius) + radius
+	p := world.NewRegularPolygon(x, y, radius, rand.Intn(6)+3, randomColor(), randomVelocity())
+	p.Filled = true
+	g.Objects = append(g.Objects, p)
+}
+
+func createCube(g *Game) *world.Cube {
 	size := rand.Float32()*70 + 10
 	x := rand.Float32() * (cube.W - size)
 	y := rand.Float32() * (cube.H - size)
-	pos := Point{x, y}.RotateAround(Point{X: cube.W / 2, Y: cube.H / 2}, cube.Rotation).Add(Point{X: cube.X, Y: cube.Y})
-	c := NewCube(pos.X, pos.Y, size, size, color.RGBA{R: uint8(rand.Intn(256)), G: uint8(rand.Intn(256)), B: uint8(rand.Intn(256)), A: 255}, Vector{rand.Float32()*2 - 1, rand.Float32()*2 - 1})
+	pos := world.Point{X: x, Y: y}.RotateAround(world.Point{X: cube.W / 2, Y: cube.H / 2}, cube.Rotation).Add(world.Point{X: cube.X, Y: cube.Y})
+	c := world.NewCube(pos.X, pos.Y, size, size, color.RGBA{R: uint8(rand.Intn(256)), G: uint8(rand.Intn(256)), B: uint8(rand.Intn(256)), A: 255}, randomVelocity())
 	g.Objects = append(g.Objects, c)
 	return c
 }
 
-var gravityConstant = float32(9.8 / fps * 2)
+func randomVelocity() world.Vector {
+	return world.Vector{X: rand.Float32()*2 - 1, Y: rand.Float32()*2 - 1}.Scale(maxSpawnSpeed)
+}
+
+func randomColor() color.RGBA {
+	return color.RGBA{R: uint8(rand.Intn(256)), G: uint8(rand.Intn(256)), B: uint8(rand.Intn(256)), A: 255}
+}
 
-func (g *Game) ApplyGravity() {
-	if !gravity {
-		return
-	}
-	for _, o := range g.Objects {
-		if c, ok := o.(*Circle); ok {
-			c.Velocity.Y += gravityConstant
-		}
+func abs(x float32) float32 {
+	if x < 0 {
+		return -x
 	}
+	return x
 }
@@ -0,0 +1,98 @@
+package levels
+
+import (
+	"image/color"
+	"log"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/ssoroka/bounce/world"
+)
+
+// metaballs draws liquids as smooth blobs that merge into each other instead of one dot per
+// particle; Y toggles it.
+var metaballs = true
+
+// metaballSize is how many particle radii a blob spreads across. Blobs have to overlap a good
+// deal for neighbouring particles to merge.
+const metaballSize = 3
+
+// thresholdSource turns the summed blobs into liquid: wherever they add up past half, it's filled
+// in with Color, with a thin soft edge.
+const thresholdSource = `//kage:unit pixels
+package main
+
+var Color vec4
+
+func Fragment(dst vec4, src vec2, color vec4) vec4 {
+	a := imageSrc0At(src).a
+	return Color * smoothstep(0.45, 0.55, a)
+}
+`
+
+var (
+	threshold *ebiten.Shader
+	// blob is a single particle's soft falloff, and field what all the blobs add up to.
+	blob  *ebiten.Image
+	field *ebiten.Image
+)
+
+// newBlob renders a round falloff of the given radius, opaque in the middle and clear at the
+// edge.
+func newBlob(radius int) *ebiten.Image {
+	size := 2 * radius
+	pixels := make([]byte, 4*size*size)
+	for y := range size {
+		for x := range size {
+			dx := (float32(x) + 0.5 - float32(radius)) / float32(radius)
+			dy := (float32(y) + 0.5 - float32(radius)) / float32(radius)
+			d := 1 - (dx*dx + dy*dy)
+			if d <= 0 {
+				continue
+			}
+			// white, premultiplied, so only the alpha matters
+			a := byte(255 * d * d)
+			i := 4 * (y*size + x)
+			pixels[i], pixels[i+1], pixels[i+2], pixels[i+3] = a, a, a, a
+		}
+	}
+	img := ebiten.NewImage(size, size)
+	img.WritePixels(pixels)
+	return img
+}
+
+// drawMetaballs adds up a blob for every particle of l and fills in where they overlap enough.
+func drawMetaballs(s *ebiten.Image, l *world.Liquid) {
+	if threshold == nil {
+		var err error
+		if threshold, err = ebiten.NewShader([]byte(thresholdSource)); err != nil {
+			log.Println("error compiling metaball shader, drawing dots instead:", err)
+			metaballs = false
+			return
+		}
+	}
+	radius := max(int(l.Radius()*metaballSize), 1)
+	if blob == nil || blob.Bounds().Dx() != 2*radius {
+		blob = newBlob(radius)
+	}
+	bounds := s.Bounds()
+	if field == nil || field.Bounds() != bounds {
+		field = ebiten.NewImage(bounds.Dx(), bounds.Dy())
+	}
+	field.Clear()
+
+	opts := &ebiten.DrawImageOptions{Blend: ebiten.BlendLighter}
+	for _, p := range l.Particles {
+		opts.GeoM.Reset()
+		opts.GeoM.Translate(float64(p.X)-float64(radius), float64(p.Y)-float64(radius))
+		field.DrawImage(blob, opts)
+	}
+
+	c := color.RGBAModel.Convert(l.Color).(color.RGBA)
+	shaderOpts := &ebiten.DrawRectShaderOptions{
+		Images: [4]*ebiten.Image{field},
+		Uniforms: map[string]any{
+			"Color": []float32{float32(c.R) / 255, float32(c.G) / 255, float32(c.B) / 255, float32(c.A) / 255},
+		},
+	}
+	s.DrawRectShader(bounds.Dx(), bounds.Dy(), threshold, shaderOpts)
+}
@@ -0,0 +1,355 @@
+package levels
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/ssoroka/bounce/world"
+)
+
+// drawObject renders any world object; the world itself knows nothing about ebiten.
+func drawObject(screen *ebiten.Image, o world.Object) {
+	switch o := o.(type) {
+	case *world.Circle:
+		drawCircle(screen, o)
+	case *world.Cube:
+		drawCube(screen, o)
+	case *world.Box:
+		drawBox(screen, o)
+	case *world.Polygon:
+		drawConvex(screen, o.Vertices(), o.Color, o.Filled)
+	case *world.Spring:
+		drawSpring(screen, o)
+	case *world.SoftBody:
+		drawSoftBody(screen, o)
+	case *world.Cloth:
+		drawCloth(screen, o)
+	case *world.Sensor:
+		drawSensor(screen, o)
+	case *world.ForceField:
+		drawForceField(screen, o)
+	case *world.Fluid:
+		drawFluid(screen, o)
+	case *world.Liquid:
+		drawLiquid(screen, o)
+	case *world.Kinematic:
+		drawKinematic(screen, o)
+	case *world.Boundary:
+		drawBoundary(screen, o)
+	case *world.CubeBoundary:
+		drawCubeBoundary(screen, o)
+	}
+	if debug {
+		drawSleeping(screen, o)
+	}
+}
+
+// drawSleeping tints whatever of o is asleep.
+func drawSleeping(s *ebiten.Image, o world.Object) {
+	switch o := o.(type) {
+	case *world.Circle:
+		if o.Sleeping {
+			vector.FillCircle(s, o.X, o.Y, o.Radius, sleepy, true)
+		}
+	case world.Convex:
+		if o.RigidBody().Sleeping {
+			drawPolygon(s, o.Vertices(), sleepy, true)
+		}
+	case world.Composite:
+		for _, p := range o.Parts() {
+			drawSleeping(s, p)
+		}
+	}
+}
+
+// normalLine returns a short line sticking out of the middle of l along its normal, for debug views.
+func normalLine(l world.Line) world.Line {
+	mid := world.Point{X: (l.From.X + l.To.X) / 2, Y: (l.From.Y + l.To.Y) / 2}
+	n := l.Normal()
+	return world.Line{From: mid, To: world.Point{X: mid.X + n.X*50, Y: mid.Y + n.Y*50}}
+}
+
+func drawCircle(s *ebiten.Image, c *world.Circle) {
+	vector.FillCircle(s, c.X, c.Y, c.Radius, c.Color, true)
+
+	// a spoke from the center shows the rotation
+	sin, cos := math.Sincos(float64(c.Angle))
+	vector.StrokeLine(s, c.X, c.Y, c.X+float32(cos)*c.Radius, c.Y+float32(sin)*c.Radius, 1, black, true)
+}
+
+func drawCube(s *ebiten.Image, c *world.Cube) {
+	p := &vector.Path{}
+	p.MoveTo(c.Points[0].X, c.Points[0].Y)
+	p.LineTo(c.Points[1].X, c.Points[1].Y)
+	p.LineTo(c.Points[2].X, c.Points[2].Y)
+	p.LineTo(c.Points[3].X, c.Points[3].Y)
+	p.Close()
+	if c.Filled {
+		vector.FillPath(s, p, &vector.FillOptions{}, &vector.DrawPathOptions{
+			AntiAlias: true,
+		})
+	} else {
+		vector.StrokePath(s, p, &vector.StrokeOptions{
+			Width: 1,
+		}, &vector.DrawPathOptions{
+			AntiAlias: true,
+		})
+	}
+
+	// draw normals
+	if debug {
+		for _, l := range c.GetLines() {
+			n := normalLine(l)
+			vector.StrokeLine(s, n.From.X, n.From.Y, n.To.X, n.To.Y, 1, green, true)
+		}
+	}
+}
+
+// drawPolygon fills or outlines the closed shape through verts in clr.
+func drawPolygon(s *ebiten.Image, verts []world.Vector, clr color.Color, filled bool) {
+	p := &vector.Path{}
+	p.MoveTo(verts[0].X, verts[0].Y)
+	for _, v := range verts[1:] {
+		p.LineTo(v.X, v.Y)
+	}
+	p.Close()
+	opts := &vector.DrawPathOptions{AntiAlias: true}
+	opts.ColorScale.ScaleWithColor(clr)
+	if filled {
+		vector.FillPath(s, p, &vector.FillOptions{}, opts)
+	} else {
+		vector.StrokePath(s, p, &vector.StrokeOptions{Width: 1}, opts)
+	}
+}
+
+func drawBox(s *ebiten.Image, b *world.Box) {
+	drawConvex(s, b.Vertices(), b.Color, b.Filled)
+}
+
+// drawConvex draws a rigid convex outline, with its face normals in debug mode.
+func drawConvex(s *ebiten.Image, verts []world.Vector, clr color.Color, filled bool) {
+	drawPolygon(s, verts, clr, filled)
+
+	if debug {
+		for i := range verts {
+			n := normalLine(world.Line{From: world.Point(verts[i]), To: world.Point(verts[(i+1)%len(verts)])})
+			vector.StrokeLine(s, n.From.X, n.From.Y, n.To.X, n.To.Y, 1, green, true)
+		}
+	}
+}
+
+func drawSpring(surf *ebiten.Image, s *world.Spring) {
+	c1, c2 := s.Ends()
+	vector.StrokeLine(surf, c1.X, c1.Y, c2.X, c2.Y, s.Thickness, s.Color, true)
+}
+
+// drawSoftBody draws the blob's skin through its nodes, with the nodes and springs in debug mode.
+func drawSoftBody(s *ebiten.Image, b *world.SoftBody) {
+	if len(b.Nodes) < 3 {
+		return
+	}
+	drawPolygon(s, b.Outline(), b.Color, b.Filled)
+
+	if debug {
+		for _, sp := range b.Springs {
+			drawSpring(s, sp)
+		}
+		for _, n := range b.Nodes {
+			vector.StrokeCircle(s, n.X, n.Y, n.Radius, 1, green, true)
+		}
+	}
+}
+
+// drawCloth draws the cloth as a mesh: its cells filled in where none of their sides have torn,
+// and a thread along every link. Pins are drawn as dots, and the nodes only in debug mode.
+func drawCloth(s *ebiten.Image, c *world.Cloth) {
+	if c.Filled {
+		faded := color.RGBAModel.Convert(c.Color).(color.RGBA)
+		faded.R, faded.G, faded.B, faded.A = faded.R/2, faded.G/2, faded.B/2, faded.A/2
+		for _, f := range c.Faces() {
+			drawPolygon(s, []world.Vector{world.Vector(f[0].Point), world.Vector(f[1].Point), world.Vector(f[2].Point), world.Vector(f[3].Point)}, faded, true)
+		}
+	}
+	for _, l := range c.Links() {
+		drawSpring(s, l)
+	}
+	for _, p := range c.Pins {
+		vector.FillCircle(s, p.At.X, p.At.Y, 3, white, true)
+	}
+
+	if debug {
+		for _, n := range c.Nodes {
+			vector.StrokeCircle(s, n.X, n.Y, n.Radius, 1, green, true)
+		}
+	}
+}
+
+// drawSensor outlines the sensor's region over a faint fill, so it's clear nothing bounces off it.
+func drawSensor(s *ebiten.Image, sensor *world.Sensor) {
+	c := color.RGBAModel.Convert(sensor.Color).(color.RGBA)
+	faint := color.RGBA{R: c.R / 4, G: c.G / 4, B: c.B / 4, A: c.A / 4}
+	switch shape := sensor.Shape.(type) {
+	case *world.Circle:
+		vector.FillCircle(s, shape.X, shape.Y, shape.Radius, faint, true)
+		vector.StrokeCircle(s, shape.X, shape.Y, shape.Radius, 1, c, true)
+	case world.Convex:
+		drawPolygon(s, shape.Vertices(), faint, true)
+		drawPolygon(s, shape.Vertices(), c, false)
+	}
+}
+
+// drawFluid fills the fluid up to its rippling surface, and draws the surface on top.
+func drawFluid(s *ebiten.Image, f *world.Fluid) {
+	c := color.RGBAModel.Convert(f.Color).(color.RGBA)
+	faint := color.RGBA{R: c.R / 3, G: c.G / 3, B: c.B / 3, A: c.A / 3}
+	surface := f.Surface()
+	verts := make([]world.Vector, 0, len(surface)+2)
+	for _, p := range surface {
+		verts = append(verts, world.Vector(p))
+	}
+	verts = append(verts, world.Vector{X: f.Region.Max.X, Y: f.Region.Max.Y}, world.Vector{X: f.Region.Min.X, Y: f.Region.Max.Y})
+	drawPolygon(s, verts, faint, true)
+	for i := 1; i < len(surface); i++ {
+		vector.StrokeLine(s, surface[i-1].X, surface[i-1].Y, surface[i].X, surface[i].Y, 2, c, true)
+	}
+}
+
+// drawLiquid draws the liquid as metaballs, or as a dot for every particle that lightens the
+// faster it goes.
+func drawLiquid(s *ebiten.Image, l *world.Liquid) {
+	if metaballs {
+		drawMetaballs(s, l)
+		if !debug {
+			return
+		}
+	}
+	c := color.RGBAModel.Convert(l.Color).(color.RGBA)
+	for _, p := range l.Particles {
+		// a splash at a few hundred pixels per second is white
+		t := min(p.Velocity.Length()/400, 1)
+		dot := color.RGBA{
+			R: c.R + uint8(t*float32(c.A-c.R)),
+			G: c.G + uint8(t*float32(c.A-c.G)),
+			B: c.B + uint8(t*float32(c.A-c.B)),
+			A: c.A,
+		}
+		vector.FillCircle(s, p.X, p.Y, l.Radius(), dot, true)
+	}
+}
+
+// fieldArrowSpacing is the distance between the arrows drawn across a force field.
+const fieldArrowSpacing = 40
+
+// drawForceField outlines the field's region or reach and fills it with arrows showing which way
+// it pushes, longer where it's stronger.
+func drawForceField(s *ebiten.Image, f *world.ForceField) {
+	c := color.RGBAModel.Convert(f.Color).(color.RGBA)
+	faint := color.RGBA{R: c.R / 2, G: c.G / 2, B: c.B / 2, A: c.A / 2}
+	bounds := f.Bounds()
+	switch {
+	case f.Region.Max.X > f.Region.Min.X:
+		r := f.Region
+		vector.StrokeRect(s, r.Min.X, r.Min.Y, r.Max.X-r.Min.X, r.Max.Y-r.Min.Y, 1, faint, false)
+	case f.Kind != world.FieldWind && f.Radius > 0:
+		vector.StrokeCircle(s, f.Center.X, f.Center.Y, f.Radius, 1, faint, true)
+	}
+	if f.Kind != world.FieldWind {
+		vector.FillCircle(s, f.Center.X, f.Center.Y, 3, c, true)
+	}
+
+	screen := s.Bounds()
+	minX, minY := max(bounds.Min.X, float32(screen.Min.X)), max(bounds.Min.Y, float32(screen.Min.Y))
+	maxX, maxY := min(bounds.Max.X, float32(screen.Max.X)), min(bounds.Max.Y, float32(screen.Max.Y))
+	strength := abs(f.Strength)
+	if strength == 0 {
+		return
+	}
+	for y := minY + fieldArrowSpacing/2; y < maxY; y += fieldArrowSpacing {
+		for x := minX + fieldArrowSpacing/2; x < maxX; x += fieldArrowSpacing {
+			a := f.Acceleration(world.Point{X: x, Y: y})
+			if a == (world.Vector{}) {
+				continue
+			}
+			length := 4 + 12*min(a.Length()/strength, 1)
+			tip := a.Normalize().Scale(length)
+			head := tip.Normalize().Scale(-4)
+			vector.StrokeLine(s, x, y, x+tip.X, y+tip.Y, 1, faint, true)
+			for _, side := range []float32{0.5, -0.5} {
+				barb := head.Rotate(side)
+				vector.StrokeLine(s, x+tip.X, y+tip.Y, x+tip.X+barb.X, y+tip.Y+barb.Y, 1, faint, true)
+			}
+		}
+	}
+}
+
+// drawSpark draws a flash that grows and fades as it ages.
+func drawSpark(s *ebiten.Image, sp spark) {
+	fade := 1 - sp.age/sparkLife
+	c := color.RGBA{R: uint8(255 * fade), G: uint8(220 * fade), B: uint8(120 * fade), A: uint8(255 * fade)}
+	vector.StrokeCircle(s, sp.at.X, sp.at.Y, 4+sp.age*40, 2, c, true)
+}
+
+// drawJoint draws a line between the joint's anchors with a dot at each, so pins and rods show.
+func drawJoint(s *ebiten.Image, j world.Joint) {
+	a, b := j.Anchors()
+	vector.StrokeLine(s, a.X, a.Y, b.X, b.Y, 1, white, true)
+	vector.FillCircle(s, a.X, a.Y, 3, white, true)
+	vector.FillCircle(s, b.X, b.Y, 3, white, true)
+}
+
+// drawKinematic draws the track a kinematic body runs along, or the pivot it spins around, under
+// its shape.
+func drawKinematic(s *ebiten.Image, k *world.Kinematic) {
+	switch m := k.Motion.(type) {
+	case world.Waypoints:
+		for i := 1; i < len(m.Points); i++ {
+			vector.StrokeLine(s, m.Points[i-1].X, m.Points[i-1].Y, m.Points[i].X, m.Points[i].Y, 1, white, true)
+		}
+		if m.Loop && len(m.Points) > 2 {
+			first, last := m.Points[0], m.Points[len(m.Points)-1]
+			vector.StrokeLine(s, last.X, last.Y, first.X, first.Y, 1, white, true)
+		}
+	case world.Spin:
+		vector.FillCircle(s, m.Pivot.X, m.Pivot.Y, 3, white, true)
+	}
+	drawObject(s, k.Shape)
+}
+
+func drawCubeBoundary(screen *ebiten.Image, b *world.CubeBoundary) {
+	for _, edge := range b.GetEdges() {
+		vector.StrokeLine(screen, edge.From.X, edge.From.Y, edge.To.X, edge.To.Y, b.StrokeWidth, b.Color, true)
+
+		// draw normals
+		if debug {
+			n := normalLine(edge)
+			vector.StrokeLine(screen, n.From.X, n.From.Y, n.To.X, n.To.Y, b.StrokeWidth, green, true)
+		}
+	}
+}
+
+// drawBoundary draws the lines, with pass-through ones faded. In debug mode each line's normals
+// stick out of the sides it's solid from: both, one, or none.
+func drawBoundary(screen *ebiten.Image, b *world.Boundary) {
+	for _, line := range b.Lines {
+		clr := color.Color(purple)
+		if line.Sides == world.PassThrough {
+			clr = faded
+		}
+		vector.StrokeLine(screen, line.From.X, line.From.Y, line.To.X, line.To.Y, 2, clr, true)
+		if !debug {
+			continue
+		}
+		switch line.Sides {
+		case world.TwoSided:
+			for _, l := range []world.Line{line, line.Flipped()} {
+				n := normalLine(l)
+				vector.StrokeLine(screen, n.From.X, n.From.Y, n.To.X, n.To.Y, b.StrokeWidth, green, true)
+			}
+		case world.OneSided:
+			n := normalLine(line)
+			vector.StrokeLine(screen, n.From.X, n.From.Y, n.To.X, n.To.Y, b.StrokeWidth, green, true)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package world
+
+const (
+	// DefaultDensity turns a shape's area into its mass in the constructors.
+	DefaultDensity     = 0.01
+	DefaultRestitution = 0.9
+	DefaultFriction    = 0.2
+)
+
+// Body is the rigid-body state shared by every dynamic shape. Point is the center of mass.
+type Body struct {
+	Point
+	LastPosition Point
+	Velocity     Vector
+	// Mass is filled in from the shape's area by its constructor. A zero Mass makes the body
+	// immovable by contacts.
+	Mass float32
+	// Restitution is the fraction of approach speed kept after a hit, 0 is dead and 1 perfectly bouncy.
+	Restitution float32
+	// Friction is the Coulomb coefficient limiting the tangential impulse at a contact.
+	Friction float32
+
+	// Angle is the orientation in radians, turning at AngularVelocity radians per second.
+	Angle           float32
+	AngularVelocity float32
+	// Inertia is the moment of inertia about the center, filled in by the shape's constructor.
+	Inertia float32
+	// Bullet opts the body into continuous collision detection, so it can't pass through thin
+	// walls or other bodies in a single step however fast it goes. Only circles are swept.
+	Bullet bool
+	// Filter decides which other objects the body collides with.
+	Filter Filter
+	// Sleeping bodies have been at rest long enough that the World stops moving and checking them
+	// until something wakes them. It isn't saved; loaded bodies start awake.
+	Sleeping bool
+
+	// sleepTime is how long the body has been at rest, in seconds.
+	sleepTime float32
+}
+
+func newBody(x, y float32, velocity Vector) Body {
+	return Body{
+		Point:        Point{X: x, Y: y},
+		LastPosition: Point{X: x, Y: y},
+		Velocity:     velocity,
+		Restitution:  DefaultRestitution,
+		Friction:     DefaultFriction,
+		Filter:       DefaultFilter,
+	}
+}
+
+// Rigid is implemented by shapes that carry a Body and respond to contact impulses.
+type Rigid interface {
+	Object
+	RigidBody() *Body
+}
+
+func (b *Body) RigidBody() *Body {
+	return b
+}
+
+func (b *Body) InvMass() float32 {
+	if b.Mass == 0 {
+		return 0
+	}
+	return 1 / b.Mass
+}
+
+func (b *Body) InvInertia() float32 {
+	if b.Inertia == 0 {
+		return 0
+	}
+	return 1 / b.Inertia
+}
+
+// VelocityAt returns the velocity of the point p on the body, including the spin.
+func (b *Body) VelocityAt(p Vector) Vector {
+	r := Vector{X: p.X - b.X, Y: p.Y - b.Y}
+	return b.Velocity.Add(r.Perp().Scale(b.AngularVelocity))
+}
+
+// ApplyImpulse changes the linear and angular velocity as if impulse was applied at the point p.
+func (b *Body) ApplyImpulse(impulse, p Vector) {
+	r := Vector{X: p.X - b.X, Y: p.Y - b.Y}
+	b.Velocity = b.Velocity.Add(impulse.Scale(b.InvMass()))
+	b.AngularVelocity += r.Cross(impulse) * b.InvInertia()
+}
+
+// integrate moves and turns the body along its velocities for delta seconds.
+func (b *Body) integrate(delta float32) {
+	b.LastPosition = b.Point
+	b.X = b.X + b.Velocity.X*delta
+	b.Y = b.Y + b.Velocity.Y*delta
+	b.Angle += b.AngularVelocity * delta
+}
@@ -1,4 +1,4 @@
-package levels
+package world
 
 import (
 	"encoding/json"
@@ -6,21 +6,31 @@ import (
 	"math"
 	"math/rand"
 	"sort"
-
-	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/vector"
 )
 
+// CubeBoundary is a box of walls that keeps bodies in. Its walls can be moved while the world
+// runs by setting Velocity, AngularVelocity and Growth, making it a container that carries, spins
+// and squeezes what's inside: bodies the walls hit are pushed at the speed the wall is moving
+// where they touch.
 type CubeBoundary struct {
 	Point
 	Size
 	Rotation    float32
 	StrokeWidth float32
 	Color       color.Color
-	tl          Point
-	tr          Point
-	bl          Point
-	br          Point
+	Filter      Filter
+	// Velocity moves the walls in pixels per second, and AngularVelocity turns them about the
+	// center in radians per second. Growth is how much bigger they get every second, as a
+	// fraction of their size; negative shrinks them.
+	Velocity        Vector
+	AngularVelocity float32
+	Growth          float32
+	// lastStep is how long the walls moved for in their last Update.
+	lastStep float32
+	tl       Point
+	tr       Point
+	bl       Point
+	br       Point
 }
 
 func NewCubeBoundary(x, y, w, h, strokeWidth float32, color color.Color) *CubeBoundary {
@@ -29,6 +39,7 @@ func NewCubeBoundary(x, y, w, h, strokeWidth float32, color color.Color) *CubeBo
 		Size:        Size{W: w, H: h},
 		StrokeWidth: strokeWidth,
 		Color:       color,
+		Filter:      DefaultFilter,
 	}
 	c.RecalculateCorners()
 	return c
@@ -81,93 +92,133 @@ func (b *CubeBoundary) RecalculateCorners() {
 	b.br = rotate(corners[3])
 }
 
-func (b *CubeBoundary) Draw(screen *ebiten.Image) {
-	vector.StrokeLine(screen, b.tl.X, b.tl.Y, b.tr.X, b.tr.Y, b.StrokeWidth, b.Color, true)
-	vector.StrokeLine(screen, b.tl.X, b.tl.Y, b.bl.X, b.bl.Y, b.StrokeWidth, b.Color, true)
-	vector.StrokeLine(screen, b.bl.X, b.bl.Y, b.br.X, b.br.Y, b.StrokeWidth, b.Color, true)
-	vector.StrokeLine(screen, b.tr.X, b.tr.Y, b.br.X, b.br.Y, b.StrokeWidth, b.Color, true)
-
-	// draw normals
-	if debug {
-		lLine, lNorm := normal(b.tl, b.bl)
-		bLine, bNorm := normal(b.bl, b.br)
-		rLine, rNorm := normal(b.br, b.tr)
-		tLine, tNorm := normal(b.tr, b.tl)
-
-		vector.StrokeLine(screen, lLine.From.X, lLine.From.Y, lLine.To.X, lLine.To.Y, b.StrokeWidth, green, true)
-		vector.StrokeLine(screen, bLine.From.X, bLine.From.Y, bLine.To.X, bLine.To.Y, b.StrokeWidth, green, true)
-		vector.StrokeLine(screen, rLine.From.X, rLine.From.Y, rLine.To.X, rLine.To.Y, b.StrokeWidth, green, true)
-		vector.StrokeLine(screen, tLine.From.X, tLine.From.Y, tLine.To.X, tLine.To.Y, b.StrokeWidth, green, true)
-		// dot(lNorm.X, 0, lNorm.Y, 0)
-		_ = lNorm
-		_ = bNorm
-		_ = rNorm
-		_ = tNorm
+func (b *CubeBoundary) Bounds() AABB {
+	return boundsOf(b.tl, b.tr, b.bl, b.br)
+}
 
-	}
+// Center is the middle of the box, which it turns and grows about.
+func (b *CubeBoundary) Center() Point {
+	return Point{X: b.X + b.W/2, Y: b.Y + b.H/2}
+}
+
+// Moving reports whether the walls are being moved.
+func (b *CubeBoundary) Moving() bool {
+	return b.Velocity != (Vector{}) || b.AngularVelocity != 0 || b.Growth != 0
+}
+
+// VelocityAt is how fast the wall is moving at p.
+func (b *CubeBoundary) VelocityAt(p Vector) Vector {
+	c := b.Center()
+	r := Vector{X: p.X - c.X, Y: p.Y - c.Y}
+	return b.Velocity.Add(r.Perp().Scale(b.AngularVelocity)).Add(r.Scale(b.Growth))
 }
 
+// Update moves the walls on by their velocity, turning and growing them.
 func (b *CubeBoundary) Update(delta float32) error {
+	b.lastStep = 0
+	if !b.Moving() {
+		return nil
+	}
+	b.lastStep = delta
+	b.X += b.Velocity.X * delta
+	b.Y += b.Velocity.Y * delta
+	b.Rotation += b.AngularVelocity * delta
+	if b.Growth != 0 {
+		b.Scale(1 + b.Growth*delta)
+	}
+	b.RecalculateCorners()
 	return nil
 }
 
-func movingToward(c *Circle, norm Vector) bool {
-	return dot(c.Velocity.X, norm.X, c.Velocity.Y, norm.Y) < 0
+// carried returns where p would be now if it had been stuck to the walls through their last
+// Update.
+func (b *CubeBoundary) carried(p Point) Point {
+	return p.Add(Point(b.VelocityAt(Vector(p)).Scale(b.lastStep)))
 }
 
-// CheckCircleCollision checks if a circle trapped within a cube boundary is colliding with the
-// boundary walls and returns collision info to keep it inside.
-func (b *CubeBoundary) CheckCircleCollision(c *Circle) Collision {
-	lLine, lNorm := normal(b.tl, b.bl)
-	bLine, bNorm := normal(b.bl, b.br)
-	rLine, rNorm := normal(b.br, b.tr)
-	tLine, tNorm := normal(b.tr, b.tl)
-
-	// check left wall
-	leftDist := dot(lNorm.X, c.X-lLine.From.X, lNorm.Y, c.Y-lLine.From.Y)
-	if leftDist < c.Radius && movingToward(c, lNorm) {
-		return Collision{
-			Hit:    true, // only consider it a collision if the circle is moving towards the wall
-			Normal: lNorm,
-			Depth:  c.Radius - leftDist,
-			Point:  Vector{X: c.X - lNorm.X*leftDist, Y: c.Y - lNorm.Y*leftDist},
-		}
-	}
+type cubeBoundaryJSON struct {
+	Type            string  `json:"type"`
+	X               float32 `json:"x"`
+	Y               float32 `json:"y"`
+	W               float32 `json:"w"`
+	H               float32 `json:"h"`
+	Rotation        float32 `json:"rotation"`
+	StrokeWidth     float32 `json:"strokeWidth"`
+	ColorR          uint8   `json:"R"`
+	ColorG          uint8   `json:"G"`
+	ColorB          uint8   `json:"B"`
+	ColorA          uint8   `json:"A"`
+	Filter          Filter  `json:"filter"`
+	Velocity        Vector  `json:"velocity"`
+	AngularVelocity float32 `json:"angularVelocity"`
+	Growth          float32 `json:"growth"`
+}
 
-	// check right wall
-	rightDist := dot(rNorm.X, c.X-rLine.From.X, rNorm.Y, c.Y-rLine.From.Y)
-	if rightDist < c.Radius && movingToward(c, rNorm) {
-		return Collision{
-			Hit:    true, // only consider it a collision if the circle is moving towards the wall
-			Normal: rNorm,
-			Depth:  c.Radius - rightDist,
-			Point:  Vector{X: c.X - rNorm.X*rightDist, Y: c.Y - rNorm.Y*rightDist},
-		}
-	}
+func (b *CubeBoundary) MarshalJSON() ([]byte, error) {
+	c := b.Color.(color.RGBA)
+	return json.Marshal(cubeBoundaryJSON{
+		Type:            "CubeBoundary",
+		X:               b.X,
+		Y:               b.Y,
+		W:               b.W,
+		H:               b.H,
+		Rotation:        b.Rotation,
+		StrokeWidth:     b.StrokeWidth,
+		ColorR:          c.R,
+		ColorG:          c.G,
+		ColorB:          c.B,
+		ColorA:          c.A,
+		Filter:          b.Filter,
+		Velocity:        b.Velocity,
+		AngularVelocity: b.AngularVelocity,
+		Growth:          b.Growth,
+	})
+}
 
-	// check top wall
-	topDist := dot(tNorm.X, c.X-tLine.From.X, tNorm.Y, c.Y-tLine.From.Y)
-	if topDist < c.Radius && movingToward(c, tNorm) {
-		return Collision{
-			Hit:    true, // only consider it a collision if the circle is moving towards the wall
-			Normal: tNorm,
-			Depth:  c.Radius - topDist,
-			Point:  Vector{X: c.X - tNorm.X*topDist, Y: c.Y - tNorm.Y*topDist},
-		}
+func (b *CubeBoundary) UnmarshalJSON(data []byte) error {
+	aux := cubeBoundaryJSON{Filter: DefaultFilter}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
 	}
 
-	// check bottom wall
-	bottomDist := dot(bNorm.X, c.X-bLine.From.X, bNorm.Y, c.Y-bLine.From.Y)
-	if bottomDist < c.Radius && movingToward(c, bNorm) {
-		return Collision{
-			Hit:    true, // only consider it a collision if the circle is moving towards the wall
-			Normal: bNorm,
-			Depth:  c.Radius - bottomDist,
-			Point:  Vector{X: c.X - bNorm.X*bottomDist, Y: c.Y - bNorm.Y*bottomDist},
+	b.Point = Point{X: aux.X, Y: aux.Y}
+	b.Size = Size{W: aux.W, H: aux.H}
+	b.Rotation = aux.Rotation
+	b.StrokeWidth = aux.StrokeWidth
+	b.Color = color.RGBA{R: aux.ColorR, G: aux.ColorG, B: aux.ColorB, A: aux.ColorA}
+	b.Filter = aux.Filter
+	b.Velocity = aux.Velocity
+	b.AngularVelocity = aux.AngularVelocity
+	b.Growth = aux.Growth
+	reserveGroup(aux.Filter.Group)
+	b.RecalculateCorners()
+	return nil
+}
+
+func movingToward(c *Circle, norm Vector) bool {
+	return dot(c.Velocity.X, norm.X, c.Velocity.Y, norm.Y) < 0
+}
+
+// CheckCircleCollision checks if a circle trapped within a cube boundary is colliding with the
+// boundary walls and returns a contact for every wall it's pressing on, so a circle in a corner is
+// pushed out of both walls at once.
+func (b *CubeBoundary) CheckCircleCollision(c *Circle) Manifold {
+	var m Manifold
+	for _, edge := range b.GetEdges() {
+		line, norm := normal(edge.From, edge.To)
+		dist := dot(norm.X, c.X-line.From.X, norm.Y, c.Y-line.From.Y)
+		// only consider it a collision if the circle is moving towards the wall. Moving walls
+		// touch whatever they overlap, so what's resting, or asleep, on them is carried along
+		if dist < c.Radius && (b.Moving() || movingToward(c, norm)) {
+			m.Add(Collision{
+				Hit:    true,
+				Normal: norm,
+				Depth:  c.Radius - dist,
+				Point:  Vector{X: c.X - norm.X*dist, Y: c.Y - norm.Y*dist},
+			})
 		}
 	}
-
-	return Collision{}
+	return m
 }
 
 func clamp(value, min, max float32) float32 {
@@ -180,10 +231,13 @@ func clamp(value, min, max float32) float32 {
 	return value
 }
 
+// Boundary is static walls made of lines. Each line can be solid from both sides, from one, or
+// not at all, by its Sides.
 type Boundary struct {
 	Lines       []Line
 	StrokeWidth float32
 	Color       color.Color
+	Filter      Filter
 }
 
 const (
@@ -196,6 +250,7 @@ func NewBoundaryLine(from, to Point, strokeWidth float32, color color.Color) *Bo
 		Lines:       []Line{{From: from, To: to}},
 		StrokeWidth: strokeWidth,
 		Color:       color,
+		Filter:      DefaultFilter,
 	}
 }
 
@@ -208,6 +263,7 @@ func (b *Boundary) MarshalJSON() ([]byte, error) {
 		ColorG      uint8   `json:"G"`
 		ColorB      uint8   `json:"B"`
 		ColorA      uint8   `json:"A"`
+		Filter      Filter  `json:"filter"`
 	}{
 		Type:        "Boundary",
 		Lines:       b.Lines,
@@ -216,6 +272,7 @@ func (b *Boundary) MarshalJSON() ([]byte, error) {
 		ColorG:      uint8(b.Color.(color.RGBA).G),
 		ColorB:      uint8(b.Color.(color.RGBA).B),
 		ColorA:      uint8(b.Color.(color.RGBA).A),
+		Filter:      b.Filter,
 	})
 }
 
@@ -228,7 +285,11 @@ func (b *Boundary) UnmarshalJSON(data []byte) error {
 		ColorG      uint8   `json:"G"`
 		ColorB      uint8   `json:"B"`
 		ColorA      uint8   `json:"A"`
-	}{}
+		Filter      Filter  `json:"filter"`
+	}{
+		// saves from before filters existed collide with everything
+		Filter: DefaultFilter,
+	}
 
 	if err := json.Unmarshal(data, &aux); err != nil {
 		return err
@@ -237,6 +298,8 @@ func (b *Boundary) UnmarshalJSON(data []byte) error {
 	b.Lines = aux.Lines
 	b.StrokeWidth = aux.StrokeWidth
 	b.Color = color.RGBA{R: aux.ColorR, G: aux.ColorG, B: aux.ColorB, A: aux.ColorA}
+	b.Filter = aux.Filter
+	reserveGroup(aux.Filter.Group)
 	return nil
 }
 
@@ -315,17 +378,18 @@ func NewBoundary(x, y, w, h, strokeWidth float32, color color.Color) *Boundary {
 	// create lines between the points and the start and end
 	appendLines(juts, start, end)
 
-	return &Boundary{Lines: lines, StrokeWidth: strokeWidth, Color: color}
+	return &Boundary{Lines: lines, StrokeWidth: strokeWidth, Color: color, Filter: DefaultFilter}
 }
 
-func (b *Boundary) Draw(screen *ebiten.Image) {
-	for _, line := range b.Lines {
-		vector.StrokeLine(screen, line.From.X, line.From.Y, line.To.X, line.To.Y, 2, purple, true)
-		lLine, _ := normal(line.From, line.To)
-		if debug {
-			vector.StrokeLine(screen, lLine.From.X, lLine.From.Y, lLine.To.X, lLine.To.Y, b.StrokeWidth, green, true)
-		}
+func (b *Boundary) Bounds() AABB {
+	if len(b.Lines) == 0 {
+		return AABB{}
 	}
+	bounds := boundsOf(b.Lines[0].From, b.Lines[0].To)
+	for _, l := range b.Lines[1:] {
+		bounds = bounds.Union(boundsOf(l.From, l.To))
+	}
+	return bounds
 }
 
 func (b *Boundary) Update(delta float32) error {
@@ -359,8 +423,15 @@ func (b *Boundary) Update(delta float32) error {
 // 	return Collision{}
 // }
 
-func (b *Boundary) CheckCircleCollision(c *Circle) Collision {
+// CheckCircleCollision returns a contact for every line the circle overlaps while moving towards
+// it, from a side the line blocks. A circle fast enough to pass right through a line in one step
+// needs to be a Bullet.
+func (b *Boundary) CheckCircleCollision(c *Circle) Manifold {
+	var m Manifold
 	for _, line := range b.Lines {
+		if !line.Blocks(c.Point) {
+			continue
+		}
 		// Find closest point on line segment to circle center
 		closestPoint := line.ClosestPoint(Vector{X: c.X, Y: c.Y})
 
@@ -370,72 +441,15 @@ func (b *Boundary) CheckCircleCollision(c *Circle) Collision {
 
 		normal := delta.Normalize()
 		if dist < c.Radius && c.Velocity.Dot(normal.Scale(-1)) > 0 {
-			return Collision{
+			m.Add(Collision{
 				Hit:    true,
 				Normal: normal,
 				Depth:  c.Radius - dist,
 				Point:  closestPoint,
-			}
+			})
 		}
 	}
-
-	startPos := c.LastPosition
-	endPos := Point{X: c.X, Y: c.Y}
-
-	for _, line := range b.Lines {
-		// Check if circle path intersects line
-		if t := raySegmentIntersect(startPos, endPos, c.Radius, line); t >= 0 {
-			// Collision at time t along movement
-			collisionPos := startPos.Add(endPos.Sub(startPos).Scale(t))
-			// calculate collision response: bounce off the line normal
-			lineVec := Vector{X: line.To.X - line.From.X, Y: line.To.Y - line.From.Y}
-			normal := Vector{X: -lineVec.Y, Y: lineVec.X}.Normalize()
-			if c.Velocity.Dot(normal.Scale(-1)) > 0 {
-				return Collision{
-					Hit:    true,
-					Normal: normal,
-					Depth:  c.Radius, // could be refined to actual penetration depth
-					Point:  Vector(collisionPos),
-				}
-			}
-		}
-	}
-
-	return Collision{}
-}
-
-func raySegmentIntersect(start, end Point, radius float32, line Line) float32 {
-	// Ray from start to end
-	rayDir := Vector{X: end.X - start.X, Y: end.Y - start.Y}
-	rayLength := rayDir.Length()
-	if rayLength == 0 {
-		return -1 // No movement
-	}
-	rayDir = rayDir.Scale(1 / rayLength) // Normalize
-
-	// Line segment vector
-	lineDir := Vector{X: line.To.X - line.From.X, Y: line.To.Y - line.From.Y}
-	lineLength := lineDir.Length()
-	if lineLength == 0 {
-		return -1 // Invalid line
-	}
-	lineDir = lineDir.Scale(1 / lineLength) // Normalize
-
-	// Calculate intersection using cross products
-	cross := rayDir.X*lineDir.Y - rayDir.Y*lineDir.X
-	if math.Abs(float64(cross)) < 1e-8 {
-		return -1 // Parallel lines
-	}
-
-	diff := Vector{X: line.From.X - start.X, Y: line.From.Y - start.Y}
-	t := (diff.X*lineDir.Y - diff.Y*lineDir.X) / cross
-	u := (diff.X*rayDir.Y - diff.Y*rayDir.X) / cross
-
-	if t >= 0 && t <= rayLength && u >= 0 && u <= lineLength {
-		return t / rayLength // Return normalized time of collision
-	}
-
-	return -1 // No collision
+	return m
 }
 
 func (l Line) ClosestPoint(p Vector) Vector {
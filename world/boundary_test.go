@@ -0,0 +1,235 @@
+package world
+
+import (
+	"image/color"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestCubeBoundaryVelocityAt(t *testing.T) {
+	b := NewCubeBoundary(0, 0, 100, 100, 1, color.RGBA{})
+	b.Velocity = Vector{X: 10}
+	b.AngularVelocity = 2
+	b.Growth = 0.5
+	// halfway down the right wall: carried along, swept down by the turn and pushed out by growth
+	got := b.VelocityAt(Vector{X: 100, Y: 50})
+	if want := (Vector{X: 10 + 25, Y: 100}); !approx(got.X, want.X) || !approx(got.Y, want.Y) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	b.Update(0.5)
+	if c := b.Center(); !approx(c.X, 55) || !approx(c.Y, 50) || !approx(b.W, 125) || !approx(b.Rotation, 1) {
+		t.Errorf("after half a second: centered at %+v, %v wide and turned %v", c, b.W, b.Rotation)
+	}
+}
+
+func TestSpinningContainerCarriesBodies(t *testing.T) {
+	w := New()
+	w.Gravity = true
+	walls := NewCubeBoundary(0, 0, 200, 200, 1, color.RGBA{})
+	ball := NewCircle(100, 185, 15, color.RGBA{}, Vector{})
+	ball.Restitution = 0
+	// the container goes first, which CheckCollision has to handle too
+	w.Add(walls, ball)
+	steps(t, w, 60)
+	if !ball.Sleeping {
+		t.Fatalf("ball should have settled on the floor, moving %+v", ball.Velocity)
+	}
+
+	walls.AngularVelocity = 1
+	steps(t, w, 6)
+	// the floor sweeps to the left under it, so friction drags it along
+	if ball.Sleeping || ball.Velocity.X > -5 {
+		t.Errorf("spinning floor should drag the ball to the left, velocity %+v", ball.Velocity)
+	}
+	// until the floor tips too steeply, and it tumbles around inside
+	steps(t, w, 120)
+	if d := Vector(ball.Point.Sub(walls.Center())).Length(); d > 100*1.42 {
+		t.Errorf("ball escaped the container, %v from its middle", d)
+	}
+}
+
+func TestShrinkingContainerPushesBodies(t *testing.T) {
+	w := New()
+	walls := NewCubeBoundary(0, 0, 100, 100, 1, color.RGBA{})
+	box := NewBox(80, 40, 18, 18, color.RGBA{}, Vector{})
+	box.Restitution = 0
+	w.Add(walls, box)
+	steps(t, w, 10)
+
+	walls.Growth = -0.5
+	steps(t, w, 30)
+	right := walls.X + walls.W
+	if box.Velocity.X > -10 {
+		t.Errorf("closing wall should push the box inwards, velocity %+v", box.Velocity)
+	}
+	if box.X+9 > right+1 {
+		t.Errorf("box at %v is outside the right wall at %v", box.X+9, right)
+	}
+}
+
+func TestGrowingContainerWakesSleepingBodies(t *testing.T) {
+	w := New()
+	w.Gravity = true
+	walls := NewCubeBoundary(0, 0, 400, 400, 1, color.RGBA{})
+	box := NewBox(190, 380, 20, 20, color.RGBA{}, Vector{})
+	box.Restitution = 0
+	w.Add(walls, box)
+	steps(t, w, 90)
+	if !box.Sleeping {
+		t.Fatalf("box should have settled on the floor, moving %+v", box.Velocity)
+	}
+
+	walls.Growth = 0.2
+	steps(t, w, 60)
+	floor := walls.Y + walls.H
+	if box.Sleeping || box.Y+10 < floor-20 {
+		t.Errorf("box should follow the floor down to %v, at %v asleep %v", floor, box.Y+10, box.Sleeping)
+	}
+}
+
+func TestCubeBoundarySaveLoad(t *testing.T) {
+	w := New()
+	walls := NewCubeBoundary(10, 20, 300, 200, 2, color.RGBA{R: 255, A: 255})
+	walls.Rotation = 0.5
+	walls.Velocity = Vector{X: 1, Y: 2}
+	walls.AngularVelocity = 3
+	walls.Growth = -0.1
+	walls.RecalculateCorners()
+	w.Add(walls)
+	filename := filepath.Join(t.TempDir(), "save.json")
+	if err := w.SaveState(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := New()
+	if err := loaded.LoadState(filename); err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Objects) != 1 {
+		t.Fatalf("got %d objects, want 1", len(loaded.Objects))
+	}
+	got, ok := loaded.Objects[0].(*CubeBoundary)
+	if !ok {
+		t.Fatalf("got %T, want *CubeBoundary", loaded.Objects[0])
+	}
+	if got.Point != walls.Point || got.Size != walls.Size || got.Rotation != walls.Rotation || got.Color != walls.Color ||
+		got.Velocity != walls.Velocity || got.AngularVelocity != walls.AngularVelocity || got.Growth != walls.Growth {
+		t.Errorf("got %+v, want %+v", *got, *walls)
+	}
+	if got.GetEdges() != walls.GetEdges() {
+		t.Errorf("corners weren't worked out again: got %+v, want %+v", got.GetEdges(), walls.GetEdges())
+	}
+}
+
+func TestLineBlocks(t *testing.T) {
+	// drawn left to right, so it faces up
+	floor := Line{From: Point{X: -10}, To: Point{X: 10}}
+	above, below := Point{Y: -5}, Point{Y: 5}
+	for _, tc := range []struct {
+		line         Line
+		above, below bool
+	}{
+		{floor, true, true},
+		{Line{From: floor.From, To: floor.To, Sides: OneSided}, true, false},
+		{Line{From: floor.From, To: floor.To, Sides: OneSided}.Flipped(), false, true},
+		{Line{From: floor.From, To: floor.To, Sides: PassThrough}, false, false},
+	} {
+		if got := tc.line.Blocks(above); got != tc.above {
+			t.Errorf("%s line %+v blocks from above: got %v, want %v", tc.line.Sides, tc.line, got, tc.above)
+		}
+		if got := tc.line.Blocks(below); got != tc.below {
+			t.Errorf("%s line %+v blocks from below: got %v, want %v", tc.line.Sides, tc.line, got, tc.below)
+		}
+	}
+}
+
+func TestOneWayPlatform(t *testing.T) {
+	for _, shape := range []string{"circle", "box"} {
+		t.Run(shape, func(t *testing.T) {
+			w := New()
+			w.Gravity = true
+			platform := NewBoundaryLine(Point{X: -100, Y: 0}, Point{X: 100, Y: 0}, 1, color.RGBA{})
+			platform.Lines[0].Sides = OneSided
+			// jumping up from below, fast enough to clear it
+			var body Rigid = NewCircle(0, 50, 10, color.RGBA{}, Vector{Y: -600})
+			if shape == "box" {
+				body = NewBox(-10, 40, 20, 20, color.RGBA{}, Vector{Y: -600})
+			}
+			body.RigidBody().Restitution = 0
+			w.Add(platform, body)
+
+			steps(t, w, 20)
+			b := body.RigidBody()
+			if b.Y > -10 {
+				t.Fatalf("should have jumped up through the platform, at %+v", b.Point)
+			}
+			steps(t, w, 120)
+			if b.Y < -11 || b.Y > -9 {
+				t.Errorf("should have landed on top of the platform, at %+v", b.Point)
+			}
+		})
+	}
+}
+
+func TestPassThroughLine(t *testing.T) {
+	w := New()
+	w.Gravity = true
+	floor := NewBoundaryLine(Point{X: -100, Y: 0}, Point{X: 100, Y: 0}, 1, color.RGBA{})
+	floor.Lines[0].Sides = PassThrough
+	ball := NewCircle(0, -20, 10, color.RGBA{}, Vector{})
+	ball.Bullet = true
+	w.Add(floor, ball)
+
+	steps(t, w, 60)
+	if ball.Y < 10 {
+		t.Errorf("ball should have fallen through, at %+v", ball.Point)
+	}
+}
+
+func TestLineSidesSaveLoad(t *testing.T) {
+	w := New()
+	b := NewBoundaryLine(Point{X: 1, Y: 2}, Point{X: 3, Y: 4}, 1, color.RGBA{A: 255})
+	b.Lines = append(b.Lines, Line{From: Point{X: 5}, To: Point{X: 6}, Sides: OneSided}, Line{To: Point{Y: 7}, Sides: PassThrough})
+	w.Add(b)
+	filename := filepath.Join(t.TempDir(), "save.json")
+	if err := w.SaveState(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := New()
+	if err := loaded.LoadState(filename); err != nil {
+		t.Fatal(err)
+	}
+	got := loaded.Objects[0].(*Boundary)
+	if !slices.Equal(got.Lines, b.Lines) {
+		t.Errorf("got lines %+v, want %+v", got.Lines, b.Lines)
+	}
+}
+
+func TestBulletStaysInSpinningContainer(t *testing.T) {
+	for _, bullet := range []bool{false, true} {
+		w := New()
+		walls := NewCubeBoundary(0, 0, 200, 200, 1, color.RGBA{})
+		walls.AngularVelocity = 3
+		// fast enough to cross the whole container in one step
+		ball := NewCircle(100, 100, 5, color.RGBA{}, Vector{X: 15000, Y: 4000})
+		ball.Bullet = bullet
+		w.Add(walls, ball)
+
+		escaped := false
+		for range 30 {
+			steps(t, w, 1)
+			for _, edge := range walls.GetEdges() {
+				// the walls face in, so outside is behind one of them
+				if Vector(ball.Point.Sub(edge.From)).Dot(edge.Normal()) < -1 {
+					escaped = true
+				}
+			}
+		}
+		if escaped != !bullet {
+			t.Errorf("bullet %v: escaped the container %v", bullet, escaped)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package world
+
+import (
+	"encoding/json"
+	"image/color"
+	"math"
+)
+
+// Box is a rigid, oriented rectangle. Unlike Cube it doesn't deform: it moves and turns as one
+// body and collides on its faces.
+type Box struct {
+	Body
+	Size
+	Color  color.Color
+	Filled bool
+}
+
+// NewBox creates an unrotated box whose top left corner is at x, y.
+func NewBox(x, y, w, h float32, color color.Color, velocity Vector) *Box {
+	b := &Box{
+		Body:  newBody(x+w/2, y+h/2, velocity),
+		Size:  Size{W: w, H: h},
+		Color: color,
+	}
+	b.Mass = b.defaultMass()
+	b.Inertia = b.defaultInertia()
+	return b
+}
+
+// defaultMass is the mass of a rectangle of DefaultDensity.
+func (b *Box) defaultMass() float32 {
+	return DefaultDensity * float32(math.Abs(float64(b.W*b.H)))
+}
+
+// defaultInertia is that of a solid rectangle about its center.
+func (b *Box) defaultInertia() float32 {
+	return b.Mass * (b.W*b.W + b.H*b.H) / 12
+}
+
+// Corners returns the corners in world space, in the order tl, tr, br, bl.
+func (b *Box) Corners() [4]Vector {
+	hw, hh := b.W/2, b.H/2
+	sin, cos := float32(math.Sin(float64(b.Angle))), float32(math.Cos(float64(b.Angle)))
+	rotate := func(x, y float32) Vector {
+		return Vector{X: b.X + x*cos - y*sin, Y: b.Y + x*sin + y*cos}
+	}
+	return [4]Vector{
+		rotate(-hw, -hh),
+		rotate(hw, -hh),
+		rotate(hw, hh),
+		rotate(-hw, hh),
+	}
+}
+
+// Axes returns the two face normals; the opposite faces are parallel so two are enough for SAT.
+func (b *Box) Axes() [2]Vector {
+	sin, cos := float32(math.Sin(float64(b.Angle))), float32(math.Cos(float64(b.Angle)))
+	return [2]Vector{
+		{X: cos, Y: sin},  // perpendicular to left/right edges
+		{X: -sin, Y: cos}, // perpendicular to top/bottom edges
+	}
+}
+
+func (b *Box) Vertices() []Vector {
+	c := b.Corners()
+	return c[:]
+}
+
+func (b *Box) FaceNormals() []Vector {
+	a := b.Axes()
+	return a[:]
+}
+
+func (b *Box) Bounds() AABB {
+	c := b.Corners()
+	return boundsOf(Point(c[0]), Point(c[1]), Point(c[2]), Point(c[3]))
+}
+
+func (b *Box) Update(delta float32) error {
+	b.integrate(delta)
+	return nil
+}
+
+type boxJSON struct {
+	Type            string  `json:"type"`
+	Point           Point   `json:"point"`
+	Size            Size    `json:"size"`
+	Angle           float32 `json:"angle"`
+	ColorR          uint8   `json:"R"`
+	ColorG          uint8   `json:"G"`
+	ColorB          uint8   `json:"B"`
+	ColorA          uint8   `json:"A"`
+	Filled          bool    `json:"filled"`
+	Velocity        Vector  `json:"velocity"`
+	AngularVelocity float32 `json:"angularVelocity"`
+	Mass            float32 `json:"mass"`
+	Inertia         float32 `json:"inertia"`
+	Restitution     float32 `json:"restitution"`
+	Friction        float32 `json:"friction"`
+	Filter          Filter  `json:"filter"`
+}
+
+func (b *Box) MarshalJSON() ([]byte, error) {
+	c := b.Color.(color.RGBA)
+	return json.Marshal(boxJSON{
+		Type:            "Box",
+		Point:           b.Point,
+		Size:            b.Size,
+		Angle:           b.Angle,
+		ColorR:          c.R,
+		ColorG:          c.G,
+		ColorB:          c.B,
+		ColorA:          c.A,
+		Filled:          b.Filled,
+		Velocity:        b.Velocity,
+		AngularVelocity: b.AngularVelocity,
+		Mass:            b.Mass,
+		Inertia:         b.Inertia,
+		Restitution:     b.Restitution,
+		Friction:        b.Friction,
+		Filter:          b.Filter,
+	})
+}
+
+func (b *Box) UnmarshalJSON(data []byte) error {
+	// saves from before filters existed collide with everything
+	aux := boxJSON{Filter: DefaultFilter}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	b.Point = aux.Point
+	b.LastPosition = aux.Point
+	b.Size = aux.Size
+	b.Angle = aux.Angle
+	b.Color = color.RGBA{R: aux.ColorR, G: aux.ColorG, B: aux.ColorB, A: aux.ColorA}
+	b.Filled = aux.Filled
+	b.Velocity = aux.Velocity
+	b.AngularVelocity = aux.AngularVelocity
+	b.Mass = aux.Mass
+	b.Inertia = aux.Inertia
+	b.Restitution = aux.Restitution
+	b.Friction = aux.Friction
+	b.Filter = aux.Filter
+	reserveGroup(aux.Filter.Group)
+	return nil
+}
@@ -0,0 +1,257 @@
+package world
+
+import (
+	"math"
+	"slices"
+)
+
+// AABB is an axis-aligned bounding box.
+type AABB struct {
+	Min Point
+	Max Point
+}
+
+func (a AABB) Overlaps(b AABB) bool {
+	return a.Min.X <= b.Max.X && a.Max.X >= b.Min.X && a.Min.Y <= b.Max.Y && a.Max.Y >= b.Min.Y
+}
+
+// Union returns the smallest box containing both a and b.
+func (a AABB) Union(b AABB) AABB {
+	return AABB{
+		Min: Point{X: min(a.Min.X, b.Min.X), Y: min(a.Min.Y, b.Min.Y)},
+		Max: Point{X: max(a.Max.X, b.Max.X), Y: max(a.Max.Y, b.Max.Y)},
+	}
+}
+
+// boundsOf returns the box around a set of points.
+func boundsOf(points ...Point) AABB {
+	b := AABB{Min: points[0], Max: points[0]}
+	for _, p := range points[1:] {
+		b = b.Union(AABB{Min: p, Max: p})
+	}
+	return b
+}
+
+// Bounded is implemented by objects that can tell the broadphase where they are. Objects that
+// don't implement it are paired with everything.
+type Bounded interface {
+	Bounds() AABB
+}
+
+// Pair is a candidate collision found by a Broadphase. A comes before B in World.Objects.
+type Pair struct {
+	A, B Object
+}
+
+// Broadphase cheaply narrows all object pairs down to the ones that might touch, so the exact
+// CheckCollision only runs on those. Implementations must return pairs in Objects order so the
+// simulation stays deterministic whichever one is used.
+type Broadphase interface {
+	Pairs(objects []Object) []Pair
+}
+
+// BruteForce tests the bounds of every pair of objects. It's the reference the faster
+// broadphases are measured against.
+type BruteForce struct{}
+
+func (BruteForce) Pairs(objects []Object) []Pair {
+	var pairs []Pair
+	for i, a := range objects {
+		for _, b := range objects[i+1:] {
+			if mayTouch(a, b) {
+				pairs = append(pairs, Pair{A: a, B: b})
+			}
+		}
+	}
+	return pairs
+}
+
+func mayTouch(a, b Object) bool {
+	ba, ok := a.(Bounded)
+	if !ok {
+		return true
+	}
+	bb, ok := b.(Bounded)
+	if !ok {
+		return true
+	}
+	return ba.Bounds().Overlaps(bb.Bounds())
+}
+
+// maxHashedCells is how many cells an object may cover before the SpatialHash stops bucketing it
+// and checks it against everything instead, which is cheaper for screen-sized boundaries.
+const maxHashedCells = 64
+
+// SpatialHash buckets objects into a uniform grid of CellSize squares and only pairs objects that
+// share a cell.
+type SpatialHash struct {
+	CellSize float32
+
+	cells map[cellKey][]int
+	seen  map[[2]int]struct{}
+	// points are what Index last bucketed.
+	points []Point
+}
+
+type cellKey struct {
+	X, Y int32
+}
+
+func NewSpatialHash(cellSize float32) *SpatialHash {
+	return &SpatialHash{
+		CellSize: cellSize,
+		cells:    map[cellKey][]int{},
+		seen:     map[[2]int]struct{}{},
+	}
+}
+
+func (h *SpatialHash) cellRange(b AABB) (minX, minY, maxX, maxY int32) {
+	minX = int32(math.Floor(float64(b.Min.X / h.CellSize)))
+	minY = int32(math.Floor(float64(b.Min.Y / h.CellSize)))
+	maxX = int32(math.Floor(float64(b.Max.X / h.CellSize)))
+	maxY = int32(math.Floor(float64(b.Max.Y / h.CellSize)))
+	return
+}
+
+func (h *SpatialHash) Pairs(objects []Object) []Pair {
+	// a zero SpatialHash works too, and Index may have made the cells already
+	if h.cells == nil {
+		h.cells = map[cellKey][]int{}
+	}
+	if h.seen == nil {
+		h.seen = map[[2]int]struct{}{}
+	}
+	// keep the buckets that were used last time, drop the ones that went empty
+	for k, v := range h.cells {
+		if len(v) == 0 {
+			delete(h.cells, k)
+		} else {
+			h.cells[k] = v[:0]
+		}
+	}
+	clear(h.seen)
+
+	bounds := make([]AABB, len(objects))
+	bounded := make([]bool, len(objects))
+	var large []int
+	for i, o := range objects {
+		b, ok := o.(Bounded)
+		if !ok {
+			large = append(large, i)
+			continue
+		}
+		bounds[i], bounded[i] = b.Bounds(), true
+		minX, minY, maxX, maxY := h.cellRange(bounds[i])
+		if int64(maxX-minX+1)*int64(maxY-minY+1) > maxHashedCells {
+			large = append(large, i)
+			continue
+		}
+		for x := minX; x <= maxX; x++ {
+			for y := minY; y <= maxY; y++ {
+				k := cellKey{X: x, Y: y}
+				h.cells[k] = append(h.cells[k], i)
+			}
+		}
+	}
+
+	var found [][2]int
+	add := func(i, j int) {
+		if i > j {
+			i, j = j, i
+		}
+		key := [2]int{i, j}
+		if _, ok := h.seen[key]; ok {
+			return
+		}
+		h.seen[key] = struct{}{}
+		if !bounded[i] || !bounded[j] || bounds[i].Overlaps(bounds[j]) {
+			found = append(found, key)
+		}
+	}
+
+	for _, bucket := range h.cells {
+		for n, i := range bucket {
+			for _, j := range bucket[n+1:] {
+				add(i, j)
+			}
+		}
+	}
+	for _, i := range large {
+		for j := range objects {
+			if i != j {
+				add(i, j)
+			}
+		}
+	}
+
+	slices.SortFunc(found, func(a, b [2]int) int {
+		if a[0] != b[0] {
+			return a[0] - b[0]
+		}
+		return a[1] - b[1]
+	})
+	pairs := make([]Pair, len(found))
+	for n, p := range found {
+		pairs[n] = Pair{A: objects[p[0]], B: objects[p[1]]}
+	}
+	return pairs
+}
+
+// Index buckets points into the grid for Within and Query, replacing whatever Pairs or an earlier
+// Index left there. Each point goes into the one cell it's in, which makes finding everything
+// within a cell of each other much cheaper than bucketing boxes.
+func (h *SpatialHash) Index(points []Point) {
+	if h.cells == nil {
+		h.cells = map[cellKey][]int{}
+	}
+	for k, v := range h.cells {
+		if len(v) == 0 {
+			delete(h.cells, k)
+		} else {
+			h.cells[k] = v[:0]
+		}
+	}
+	h.points = points
+	for i, p := range points {
+		k := h.cellOf(p)
+		h.cells[k] = append(h.cells[k], i)
+	}
+}
+
+func (h *SpatialHash) cellOf(p Point) cellKey {
+	return cellKey{X: int32(math.Floor(float64(p.X / h.CellSize))), Y: int32(math.Floor(float64(p.Y / h.CellSize)))}
+}
+
+// Within calls fn with every pair of indexed points closer than CellSize, the lower index first,
+// looking only in each point's own cell and the eight around it.
+func (h *SpatialHash) Within(fn func(i, j int)) {
+	reach := h.CellSize * h.CellSize
+	for i, p := range h.points {
+		k := h.cellOf(p)
+		for x := k.X - 1; x <= k.X+1; x++ {
+			for y := k.Y - 1; y <= k.Y+1; y++ {
+				for _, j := range h.cells[cellKey{X: x, Y: y}] {
+					if j <= i {
+						continue
+					}
+					if d := Vector(h.points[j].Sub(p)); d.Dot(d) < reach {
+						fn(i, j)
+					}
+				}
+			}
+		}
+	}
+}
+
+// Query calls fn with every indexed point in the cells b covers, which includes every point
+// inside b.
+func (h *SpatialHash) Query(b AABB, fn func(i int)) {
+	minX, minY, maxX, maxY := h.cellRange(b)
+	for x := minX; x <= maxX; x++ {
+		for y := minY; y <= maxY; y++ {
+			for _, i := range h.cells[cellKey{X: x, Y: y}] {
+				fn(i)
+			}
+		}
+	}
+}
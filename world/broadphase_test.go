@@ -0,0 +1,93 @@
+package world
+
+import (
+	"image/color"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func randomScene(n int, r *rand.Rand) []Object {
+	objects := []Object{NewBoundary(0, 0, 1000, 1000, 1, color.RGBA{})}
+	for range n {
+		radius := r.Float32()*20 + 2
+		objects = append(objects, NewCircle(r.Float32()*1000, r.Float32()*1000, radius, color.RGBA{}, Vector{}))
+	}
+	objects = append(objects, NewCube(500, 500, 80, 80, color.RGBA{}, Vector{}))
+	return objects
+}
+
+func TestSpatialHashMatchesBruteForce(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	hash := NewSpatialHash(32)
+	for round := range 3 {
+		objects := randomScene(400, r)
+		want := BruteForce{}.Pairs(objects)
+		got := hash.Pairs(objects)
+		if !slices.Equal(got, want) {
+			t.Fatalf("round %d: spatial hash found %d pairs, brute force %d", round, len(got), len(want))
+		}
+		if len(got) == 0 {
+			t.Fatalf("round %d: expected some overlapping pairs", round)
+		}
+	}
+}
+
+func TestSpatialHashWithin(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	points := make([]Point, 500)
+	for i := range points {
+		points[i] = Point{X: r.Float32()*400 - 200, Y: r.Float32()*400 - 200}
+	}
+	var want [][2]int
+	for i, p := range points {
+		for j := i + 1; j < len(points); j++ {
+			if d := Vector(points[j].Sub(p)); d.Length() < 16 {
+				want = append(want, [2]int{i, j})
+			}
+		}
+	}
+
+	hash := NewSpatialHash(16)
+	hash.Index(points)
+	var got [][2]int
+	hash.Within(func(i, j int) { got = append(got, [2]int{i, j}) })
+	slices.SortFunc(got, func(a, b [2]int) int {
+		if a[0] != b[0] {
+			return a[0] - b[0]
+		}
+		return a[1] - b[1]
+	})
+	if len(want) == 0 || !slices.Equal(got, want) {
+		t.Errorf("found %d close pairs, want %d", len(got), len(want))
+	}
+}
+
+func TestZeroSpatialHash(t *testing.T) {
+	// the liquid's Index can be the first to use a hash built without NewSpatialHash
+	hash := &SpatialHash{CellSize: 32}
+	hash.Index([]Point{{X: 1, Y: 1}, {X: 2, Y: 2}})
+	objects := randomScene(50, rand.New(rand.NewSource(3)))
+	if got, want := hash.Pairs(objects), (BruteForce{}).Pairs(objects); !slices.Equal(got, want) {
+		t.Errorf("spatial hash found %d pairs, brute force %d", len(got), len(want))
+	}
+}
+
+func BenchmarkCheckCollisions(b *testing.B) {
+	for _, bench := range []struct {
+		name       string
+		broadphase Broadphase
+	}{
+		{"BruteForce", BruteForce{}},
+		{"SpatialHash", NewSpatialHash(defaultCellSize)},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			w := New()
+			w.Broadphase = bench.broadphase
+			w.Objects = randomScene(2000, rand.New(rand.NewSource(1)))
+			for b.Loop() {
+				w.CheckCollisions()
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package world
+
+import "math"
+
+// sweepBullets stops fast circles from tunneling. Every awake Bullet circle is swept from its
+// LastPosition to where it ended the step, against the Boundary lines, CubeBoundary walls and
+// other circles the broadphase paired it with, which found them with pathBounds. At the earliest
+// time of impact both bodies are moved back to where they were at that moment and a touching
+// contact is returned, by pair, for the solver to resolve. The remainder of that step's motion is
+// dropped.
+func sweepBullets(objects []Object, pairs []Pair) map[Pair]Manifold {
+	candidates := make(map[*Circle][]Pair)
+	for _, pair := range pairs {
+		for _, o := range [2]Object{pair.A, pair.B} {
+			if c, ok := o.(*Circle); ok && c.Bullet && !c.Sleeping {
+				candidates[c] = append(candidates[c], pair)
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	swept := make(map[Pair]Manifold)
+	// go through the bullets in Objects order, so the simulation stays deterministic
+	for _, o := range objects {
+		c, ok := o.(*Circle)
+		if !ok || candidates[c] == nil {
+			continue
+		}
+
+		toi := float32(1)
+		var first Pair
+		var contact Manifold
+		var other *Circle
+		// at is where the bullet was at toi
+		var at Point
+		for _, pair := range candidates[c] {
+			o2 := pair.A
+			if o2 == Object(c) {
+				o2 = pair.B
+			}
+			if !canCollide(c, o2) {
+				continue
+			}
+			switch sb := o2.(type) {
+			case *Boundary:
+				for _, line := range sb.Lines {
+					if !line.Blocks(c.LastPosition) {
+						continue
+					}
+					if t, ok := sweepCircleSegment(Vector(c.LastPosition), Vector(c.Point), c.Radius, line); ok && t < toi {
+						toi, other, first, at = t, nil, pair, positionAt(&c.Body, t)
+						contact = single(segmentContact(at, line))
+					}
+				}
+			case *CubeBoundary:
+				// sweep in the frame of the walls as they are now, from where the walls carried
+				// the start of the move to, and only from inside
+				start := sb.carried(c.LastPosition)
+				for _, line := range sb.GetEdges() {
+					line.Sides = OneSided
+					if !line.Blocks(start) {
+						continue
+					}
+					if t, ok := sweepCircleSegment(Vector(start), Vector(c.Point), c.Radius, line); ok && t < toi {
+						toi, other, first, at = t, nil, pair, start.Add(c.Point.Sub(start).Scale(t))
+						contact = single(segmentContact(at, line))
+					}
+				}
+			case *Circle:
+				if t, ok := sweepCircles(c, sb); ok && t < toi {
+					toi, other, first, at = t, sb, pair, positionAt(&c.Body, t)
+				}
+			}
+		}
+		if toi == 1 {
+			continue
+		}
+
+		c.Point = at
+		if other != nil {
+			other.Point = positionAt(&other.Body, toi)
+			a, b := c, other
+			if first.A != Object(c) {
+				a, b = other, c
+			}
+			normal := Vector(b.Point.Sub(a.Point)).Normalize()
+			contact = single(Collision{
+				Hit:    true,
+				Normal: normal,
+				Point:  Vector(a.Point).Add(normal.Scale(a.Radius)),
+			})
+		}
+		swept[first] = contact
+	}
+	return swept
+}
+
+// pathBounds is a circle's box for the broadphase, stretched over the whole of its last step, so
+// the pairs found hold everything a bullet could have hit on the way, and still hold after
+// sweepBullets moves circles back along their paths.
+type pathBounds struct {
+	*Circle
+}
+
+func (p pathBounds) Bounds() AABB {
+	b, r := boundsOf(p.LastPosition, p.Point), p.Radius
+	return AABB{
+		Min: Point{X: b.Min.X - r, Y: b.Min.Y - r},
+		Max: Point{X: b.Max.X + r, Y: b.Max.Y + r},
+	}
+}
+
+// broadphasePairs runs the broadphase with every moving circle covering its whole step.
+func broadphasePairs(broadphase Broadphase, objects []Object) []Pair {
+	stretched := make([]Object, len(objects))
+	for i, o := range objects {
+		stretched[i] = o
+		if c, ok := o.(*Circle); ok && c.LastPosition != c.Point {
+			stretched[i] = pathBounds{c}
+		}
+	}
+	pairs := broadphase.Pairs(stretched)
+	for i, pair := range pairs {
+		if p, ok := pair.A.(pathBounds); ok {
+			pairs[i].A = p.Circle
+		}
+		if p, ok := pair.B.(pathBounds); ok {
+			pairs[i].B = p.Circle
+		}
+	}
+	return pairs
+}
+
+// positionAt returns where the body was at time t of its last step, between 0 at LastPosition and
+// 1 at its current position.
+func positionAt(b *Body, t float32) Point {
+	return b.LastPosition.Add(b.Point.Sub(b.LastPosition).Scale(t))
+}
+
+// segmentContact is the touching contact of a circle centered at center with a line, with the normal
+// pointing from the line to the circle.
+func segmentContact(center Point, line Line) Collision {
+	closest := line.ClosestPoint(Vector(center))
+	return Collision{
+		Hit:    true,
+		Normal: Vector(center).Sub(closest).Normalize(),
+		Point:  closest,
+	}
+}
+
+// sweepCircleSegment returns the time, between 0 and 1, at which a circle moving from start to
+// end first touches the line. That's when its center enters the capsule of points within radius
+// of the line: either one of the capsule's flat sides or one of the round caps at its ends. A
+// circle that already overlaps the line at start is left to the discrete test.
+func sweepCircleSegment(start, end Vector, radius float32, line Line) (float32, bool) {
+	if start.Sub(line.ClosestPoint(start)).Length() < radius {
+		return 0, false
+	}
+	move := end.Sub(start)
+	toi := float32(math.MaxFloat32)
+
+	// the flat side facing the start of the move
+	n := line.Normal()
+	from := Vector(line.From)
+	side := start.Sub(from).Dot(n)
+	if side < 0 {
+		n, side = n.Scale(-1), -side
+	}
+	if approach := move.Dot(n); approach < 0 {
+		t := (side - radius) / -approach
+		hit := start.Add(move.Scale(t))
+		if along := hit.Sub(from).Dot(line.Normalized()); along >= 0 && along <= line.Length() {
+			toi = t
+		}
+	}
+
+	// the caps at either end
+	for _, p := range []Point{line.From, line.To} {
+		if t, ok := rayCircle(start, move, Vector(p), radius); ok {
+			toi = min(toi, t)
+		}
+	}
+
+	if toi > 1 {
+		return 0, false
+	}
+	return toi, true
+}
+
+// sweepCircles returns the time, between 0 and 1, at which two circles moving from their
+// LastPositions to their current ones first touch. Relative to b, a travels in a straight line,
+// so it's a ray against a circle of both radii around b.
+func sweepCircles(a, b *Circle) (float32, bool) {
+	start := Vector(a.LastPosition.Sub(b.LastPosition))
+	if start.Length() < a.Radius+b.Radius {
+		return 0, false
+	}
+	move := Vector(a.Point.Sub(a.LastPosition)).Sub(Vector(b.Point.Sub(b.LastPosition)))
+	t, ok := rayCircle(start, move, Vector{}, a.Radius+b.Radius)
+	if !ok || t > 1 {
+		return 0, false
+	}
+	return t, true
+}
+
+// rayCircle returns the first time t >= 0 at which start + t*move is radius away from center.
+func rayCircle(start, move, center Vector, radius float32) (float32, bool) {
+	s := start.Sub(center)
+	a := move.Dot(move)
+	if a == 0 {
+		return 0, false
+	}
+	b := 2 * s.Dot(move)
+	c := s.Dot(s) - radius*radius
+	disc := b*b - 4*a*c
+	if disc < 0 {
+		return 0, false
+	}
+	t := (-b - float32(math.Sqrt(float64(disc)))) / (2 * a)
+	if t < 0 {
+		return 0, false
+	}
+	return t, true
+}
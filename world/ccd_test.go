@@ -0,0 +1,89 @@
+package world
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestSweepCircleSegment(t *testing.T) {
+	line := Line{From: Point{X: 0, Y: 100}, To: Point{X: 100, Y: 100}}
+
+	toi, ok := sweepCircleSegment(Vector{X: 50, Y: 0}, Vector{X: 50, Y: 200}, 10, line)
+	if !ok || !approx(toi, 0.45) {
+		t.Errorf("straight through the middle: got %.3f, %v, want 0.45", toi, ok)
+	}
+
+	// past the end of the line, only the round cap at To is in the way
+	toi, ok = sweepCircleSegment(Vector{X: 105, Y: 0}, Vector{X: 105, Y: 200}, 10, line)
+	if !ok || toi <= 0.45 || toi >= 0.5 {
+		t.Errorf("grazing the end: got %.3f, %v, want just after 0.45", toi, ok)
+	}
+
+	if _, ok := sweepCircleSegment(Vector{X: 50, Y: 0}, Vector{X: 50, Y: 80}, 10, line); ok {
+		t.Error("stopping short of the line should not hit")
+	}
+	if _, ok := sweepCircleSegment(Vector{X: 150, Y: 0}, Vector{X: 150, Y: 200}, 10, line); ok {
+		t.Error("passing beside the line should not hit")
+	}
+}
+
+func TestBulletDoesNotTunnel(t *testing.T) {
+	for _, bullet := range []bool{false, true} {
+		w := New()
+		wall := NewBoundaryLine(Point{X: 100, Y: -100}, Point{X: 100, Y: 100}, 1, color.RGBA{})
+		ball := NewCircle(0, 0, 5, color.RGBA{}, Vector{X: 9000})
+		ball.Bullet = bullet
+		w.Add(wall, ball)
+
+		for range 3 {
+			if err := w.Step(w.TimeStep); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if through := ball.X > 100; through == bullet {
+			t.Errorf("bullet %v: ball ended at x = %.2f", bullet, ball.X)
+		}
+		if bullet && ball.Velocity.X >= 0 {
+			t.Errorf("bullet should bounce off the wall, velocity %+v", ball.Velocity)
+		}
+	}
+}
+
+func TestBulletHitsCircle(t *testing.T) {
+	w := New()
+	bullet := NewCircle(0, 0, 2, color.RGBA{}, Vector{X: 12000})
+	bullet.Bullet = true
+	target := NewCircle(100, 0, 5, color.RGBA{}, Vector{})
+	w.Add(bullet, target)
+
+	if err := w.Step(w.TimeStep); err != nil {
+		t.Fatal(err)
+	}
+	if bullet.X > target.X {
+		t.Errorf("bullet passed through the target: bullet at %.2f, target at %.2f", bullet.X, target.X)
+	}
+	if target.Velocity.X <= 0 {
+		t.Errorf("target should be knocked along, velocity %+v", target.Velocity)
+	}
+}
+
+func TestBroadphasePairsCoverTheWholeStep(t *testing.T) {
+	wall := NewBoundaryLine(Point{X: 100, Y: -100}, Point{X: 100, Y: 100}, 1, color.RGBA{})
+	ball := NewCircle(200, 0, 5, color.RGBA{}, Vector{})
+	ball.LastPosition = Point{X: 0, Y: 0}
+	ball.Bullet = true
+	far := NewCircle(500, 0, 5, color.RGBA{}, Vector{})
+	objects := []Object{wall, ball, far}
+
+	pairs := broadphasePairs(NewSpatialHash(defaultCellSize), objects)
+	if len(pairs) != 1 || pairs[0] != (Pair{A: wall, B: ball}) {
+		t.Fatalf("got pairs %+v, want just the wall the ball went through", pairs)
+	}
+	if swept := sweepBullets(objects, nil); len(swept) != 0 {
+		t.Errorf("bullets should only be swept against their candidates, got %+v", swept)
+	}
+	swept := sweepBullets(objects, pairs)
+	if _, ok := swept[pairs[0]]; !ok || ball.X > 100 {
+		t.Errorf("ball should be stopped at the wall: at %+v, swept %+v", ball.Point, swept)
+	}
+}
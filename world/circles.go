@@ -0,0 +1,138 @@
+package world
+
+import (
+	"encoding/json"
+	"image/color"
+	"math"
+)
+
+type Circle struct {
+	Body
+	Radius float32
+	Color  color.Color
+}
+
+func NewCircle(x, y, radius float32, color color.Color, velocity Vector) *Circle {
+	c := &Circle{
+		Body:   newBody(x, y, velocity),
+		Radius: radius,
+		Color:  color,
+	}
+	c.Mass = c.defaultMass()
+	c.Inertia = c.defaultInertia()
+	return c
+}
+
+// defaultMass is the mass of a disc of DefaultDensity with the circle's radius.
+func (c *Circle) defaultMass() float32 {
+	return DefaultDensity * math.Pi * c.Radius * c.Radius
+}
+
+// defaultInertia is that of a solid disc with the circle's mass and radius.
+func (c *Circle) defaultInertia() float32 {
+	return c.Mass * c.Radius * c.Radius / 2
+}
+
+func (c *Circle) Bounds() AABB {
+	return AABB{
+		Min: Point{X: c.X - c.Radius, Y: c.Y - c.Radius},
+		Max: Point{X: c.X + c.Radius, Y: c.Y + c.Radius},
+	}
+}
+
+func (c *Circle) Update(delta float32) error {
+	c.integrate(delta)
+	return nil
+}
+
+func (c *Circle) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type            string  `json:"type"`
+		Point           Point   `json:"point"`
+		Radius          float32 `json:"radius"`
+		ColorR          uint8   `json:"R"`
+		ColorG          uint8   `json:"G"`
+		ColorB          uint8   `json:"B"`
+		ColorA          uint8   `json:"A"`
+		Velocity        Vector  `json:"velocity"`
+		Mass            float32 `json:"mass"`
+		Restitution     float32 `json:"restitution"`
+		Friction        float32 `json:"friction"`
+		Angle           float32 `json:"angle"`
+		AngularVelocity float32 `json:"angularVelocity"`
+		Inertia         float32 `json:"inertia"`
+		Bullet          bool    `json:"bullet,omitempty"`
+		Filter          Filter  `json:"filter"`
+	}{
+		Type:            "Circle",
+		Point:           c.Point,
+		Radius:          c.Radius,
+		ColorR:          uint8(c.Color.(color.RGBA).R),
+		ColorG:          uint8(c.Color.(color.RGBA).G),
+		ColorB:          uint8(c.Color.(color.RGBA).B),
+		ColorA:          uint8(c.Color.(color.RGBA).A),
+		Velocity:        c.Velocity,
+		Mass:            c.Mass,
+		Restitution:     c.Restitution,
+		Friction:        c.Friction,
+		Angle:           c.Angle,
+		AngularVelocity: c.AngularVelocity,
+		Inertia:         c.Inertia,
+		Bullet:          c.Bullet,
+		Filter:          c.Filter,
+	})
+}
+
+func (c *Circle) UnmarshalJSON(data []byte) error {
+	aux := struct {
+		Type            string   `json:"type"`
+		Point           Point    `json:"point"`
+		Radius          float32  `json:"radius"`
+		ColorR          uint8    `json:"R"`
+		ColorG          uint8    `json:"G"`
+		ColorB          uint8    `json:"B"`
+		ColorA          uint8    `json:"A"`
+		Velocity        Vector   `json:"velocity"`
+		Mass            *float32 `json:"mass"`
+		Restitution     float32  `json:"restitution"`
+		Friction        float32  `json:"friction"`
+		Angle           float32  `json:"angle"`
+		AngularVelocity float32  `json:"angularVelocity"`
+		Inertia         *float32 `json:"inertia"`
+		Bullet          bool     `json:"bullet,omitempty"`
+		Filter          Filter   `json:"filter"`
+	}{
+		// saves from before these settings existed get the defaults
+		Restitution: DefaultRestitution,
+		Friction:    DefaultFriction,
+		Filter:      DefaultFilter,
+	}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	c.Point = aux.Point
+	c.Radius = aux.Radius
+	c.Color = color.RGBA{R: aux.ColorR, G: aux.ColorG, B: aux.ColorB, A: aux.ColorA}
+	c.Velocity = aux.Velocity
+	c.LastPosition = aux.Point
+	// a mass of zero is a static circle, so only saves without one get the default
+	c.Mass = c.defaultMass()
+	if aux.Mass != nil {
+		c.Mass = *aux.Mass
+	}
+	c.Restitution = aux.Restitution
+	c.Friction = aux.Friction
+	c.Angle = aux.Angle
+	c.AngularVelocity = aux.AngularVelocity
+	c.Inertia = c.defaultInertia()
+	if aux.Inertia != nil {
+		c.Inertia = *aux.Inertia
+	}
+	c.Bullet = aux.Bullet
+	c.Filter = aux.Filter
+	reserveGroup(aux.Filter.Group)
+
+	return nil
+}
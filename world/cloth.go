@@ -0,0 +1,249 @@
+package world
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+)
+
+const (
+	// clothStiffness and clothDamping are per unit of node mass, like the Cube's springs. A hanging
+	// rope's top link carries the weight of every node below it, so the links are far stiffer than
+	// the Cube's, which is only stable because stepNodes steps them clothSubsteps times per step.
+	clothStiffness = 20000
+	clothDamping   = 40
+	clothSubsteps  = 8
+	// Shear springs keep the cells from folding flat and bend springs keep the sheet from creasing.
+	// Both are softer than the structural links, so cloth still drapes.
+	clothShearStiffness = clothStiffness / 4
+	clothBendStiffness  = clothStiffness / 8
+	// ropeBendStiffness only just stops a rope kinking, so it hangs slack instead of like a rod.
+	ropeBendStiffness = clothStiffness / 100
+	// DefaultTearRatio tears a cloth spring stretched to this many times its rest length.
+	DefaultTearRatio = 2
+)
+
+// Cloth is a grid of Circle nodes, Cols wide and Rows tall, held together by springs: structural
+// links to each node's neighbours, shear springs across each cell's diagonals and bend springs to
+// the node two along. A rope is a Cloth one row tall. Springs tear when overstretched, and some
+// nodes can be pinned to fixed points in the world. The nodes collide on their own, as parts of
+// the cloth.
+type Cloth struct {
+	// Nodes are in rows, so the node at column c of row r is Nodes[r*Cols+c].
+	Nodes   []*Circle
+	Cols    int
+	Rows    int
+	Springs []*Spring
+	Pins    []Pin
+	Color   color.Color
+	Filled  bool
+}
+
+// Pin holds a node of a Cloth at a fixed point in the world.
+type Pin struct {
+	Node int   `json:"node"`
+	At   Point `json:"at"`
+}
+
+// NewCloth creates a sheet of cols by rows nodes spaced spacing apart, with its top left node at
+// x, y, and at least one of each. Nothing is pinned, so pin some nodes before letting it fall.
+func NewCloth(x, y float32, cols, rows int, spacing float32, color color.Color) *Cloth {
+	cols, rows = max(cols, 1), max(rows, 1)
+	points := make([]Point, 0, cols*rows)
+	for r := range rows {
+		for c := range cols {
+			points = append(points, Point{X: x + float32(c)*spacing, Y: y + float32(r)*spacing})
+		}
+	}
+	return newCloth(points, cols, rows, spacing/4, clothBendStiffness, color)
+}
+
+// NewRope creates a chain of at least two nodes evenly spaced from one point to another, with its
+// first node pinned where it starts.
+func NewRope(from, to Point, nodes int, color color.Color) *Cloth {
+	nodes = max(nodes, 2)
+	step := to.Sub(from).Scale(1 / float32(nodes-1))
+	points := make([]Point, nodes)
+	for i := range points {
+		points[i] = from.Add(step.Scale(float32(i)))
+	}
+	rope := newCloth(points, nodes, 1, Vector(step).Length()/4, ropeBendStiffness, color)
+	rope.Pin(0, from)
+	return rope
+}
+
+// newCloth joins a grid of nodes at points, in rows, with its springs.
+func newCloth(points []Point, cols, rows int, radius, bendStiffness float32, color color.Color) *Cloth {
+	cloth := &Cloth{Cols: cols, Rows: rows, Color: color}
+	for _, p := range points {
+		cloth.Nodes = append(cloth.Nodes, NewCircle(p.X, p.Y, max(radius, 1), color, Vector{}))
+	}
+	mass := cloth.Nodes[0].Mass
+	link := func(c1, r1, c2, r2 int, stiffness float32) {
+		if c2 < 0 || c2 >= cols || r2 >= rows {
+			return
+		}
+		s := NewSpring(cloth.Node(c1, r1), cloth.Node(c2, r2), stiffness*mass, 1, color)
+		s.Damping = clothDamping * mass
+		s.BreakRatio = DefaultTearRatio
+		cloth.Springs = append(cloth.Springs, s)
+	}
+	for r := range rows {
+		for c := range cols {
+			link(c, r, c+1, r, clothStiffness)
+			link(c, r, c, r+1, clothStiffness)
+			link(c, r, c+1, r+1, clothShearStiffness)
+			link(c, r, c-1, r+1, clothShearStiffness)
+			link(c, r, c+2, r, bendStiffness)
+			link(c, r, c, r+2, bendStiffness)
+		}
+	}
+	return cloth
+}
+
+// Node returns the node at column c of row r.
+func (cl *Cloth) Node(c, r int) *Circle {
+	return cl.Nodes[r*cl.Cols+c]
+}
+
+// Pin holds node i at the world point at. A pinned node is immovable, so springs and contacts
+// can't drag it away.
+func (cl *Cloth) Pin(i int, at Point) {
+	cl.Nodes[i].Mass = 0
+	cl.Nodes[i].Point = at
+	cl.Nodes[i].Velocity = Vector{}
+	cl.Pins = append(cl.Pins, Pin{Node: i, At: at})
+}
+
+// neighbours reports whether nodes i and j are next to each other in a row or a column.
+func (cl *Cloth) neighbours(i, j int) bool {
+	d := max(i, j) - min(i, j)
+	return (d == 1 && min(i, j)%cl.Cols != cl.Cols-1) || d == cl.Cols
+}
+
+// Links returns the structural springs that haven't torn, the threads a renderer draws.
+func (cl *Cloth) Links() []*Spring {
+	index := cl.index()
+	var links []*Spring
+	for _, s := range cl.Springs {
+		from, to := s.Ends()
+		if !s.Broken && cl.neighbours(index[from], index[to]) {
+			links = append(links, s)
+		}
+	}
+	return links
+}
+
+// Faces returns the corners of every cell whose four sides are still linked, in order round the
+// cell, so a renderer can fill in the cloth and leave holes where it tore.
+func (cl *Cloth) Faces() [][4]*Circle {
+	index := cl.index()
+	linked := make(map[[2]int]bool)
+	for _, s := range cl.Links() {
+		from, to := s.Ends()
+		i, j := index[from], index[to]
+		linked[[2]int{min(i, j), max(i, j)}] = true
+	}
+	var faces [][4]*Circle
+	for r := range cl.Rows - 1 {
+		for c := range cl.Cols - 1 {
+			i := r*cl.Cols + c
+			if linked[[2]int{i, i + 1}] && linked[[2]int{i, i + cl.Cols}] &&
+				linked[[2]int{i + 1, i + 1 + cl.Cols}] && linked[[2]int{i + cl.Cols, i + 1 + cl.Cols}] {
+				faces = append(faces, [4]*Circle{cl.Node(c, r), cl.Node(c+1, r), cl.Node(c+1, r+1), cl.Node(c, r+1)})
+			}
+		}
+	}
+	return faces
+}
+
+func (cl *Cloth) index() map[*Circle]int {
+	index := make(map[*Circle]int, len(cl.Nodes))
+	for i, n := range cl.Nodes {
+		index[n] = i
+	}
+	return index
+}
+
+// Parts returns the nodes, which collide on their own.
+func (cl *Cloth) Parts() []Object {
+	parts := make([]Object, len(cl.Nodes))
+	for i, n := range cl.Nodes {
+		parts[i] = n
+	}
+	return parts
+}
+
+func (cl *Cloth) Bounds() AABB {
+	b := cl.Nodes[0].Bounds()
+	for _, n := range cl.Nodes[1:] {
+		b = b.Union(n.Bounds())
+	}
+	return b
+}
+
+func (cl *Cloth) Update(delta float32) error {
+	// gravity still pulls on pinned nodes, so put them back
+	for _, p := range cl.Pins {
+		if p.Node >= 0 && p.Node < len(cl.Nodes) {
+			cl.Nodes[p.Node].Point = p.At
+			cl.Nodes[p.Node].Velocity = Vector{}
+		}
+	}
+	return stepNodes(cl.Nodes, cl.Springs, delta, clothSubsteps, nil)
+}
+
+type clothJSON struct {
+	Type    string           `json:"type"`
+	Nodes   []*Circle        `json:"nodes"`
+	Cols    int              `json:"cols"`
+	Rows    int              `json:"rows"`
+	Springs []nodeSpringJSON `json:"springs"`
+	Pins    []Pin            `json:"pins,omitempty"`
+	ColorR  uint8            `json:"R"`
+	ColorG  uint8            `json:"G"`
+	ColorB  uint8            `json:"B"`
+	ColorA  uint8            `json:"A"`
+	Filled  bool             `json:"filled"`
+}
+
+func (cl *Cloth) MarshalJSON() ([]byte, error) {
+	c := cl.Color.(color.RGBA)
+	return json.Marshal(clothJSON{
+		Type:    "Cloth",
+		Nodes:   cl.Nodes,
+		Cols:    cl.Cols,
+		Rows:    cl.Rows,
+		Springs: marshalNodeSprings(cl.Nodes, cl.Springs),
+		Pins:    cl.Pins,
+		ColorR:  c.R,
+		ColorG:  c.G,
+		ColorB:  c.B,
+		ColorA:  c.A,
+		Filled:  cl.Filled,
+	})
+}
+
+func (cl *Cloth) UnmarshalJSON(data []byte) error {
+	var aux clothJSON
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	if aux.Cols < 1 || aux.Rows < 1 || len(aux.Nodes) != aux.Cols*aux.Rows {
+		return fmt.Errorf("cloth has %d nodes, want %d columns by %d rows of at least one", len(aux.Nodes), aux.Cols, aux.Rows)
+	}
+
+	cl.Nodes = aux.Nodes
+	cl.Cols = aux.Cols
+	cl.Rows = aux.Rows
+	cl.Color = color.RGBA{R: aux.ColorR, G: aux.ColorG, B: aux.ColorB, A: aux.ColorA}
+	cl.Springs = unmarshalNodeSprings(cl.Nodes, aux.Springs, cl.Color)
+	cl.Pins = nil
+	for _, p := range aux.Pins {
+		if p.Node >= 0 && p.Node < len(cl.Nodes) {
+			cl.Pin(p.Node, p.At)
+		}
+	}
+	cl.Filled = aux.Filled
+	return nil
+}
@@ -0,0 +1,139 @@
+package world
+
+import (
+	"image/color"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// hang steps a gravity world holding cloth for seconds, calling check after every step.
+func hang(t *testing.T, cloth *Cloth, seconds float32, check func()) {
+	t.Helper()
+	w := New()
+	w.Gravity = true
+	w.Add(cloth)
+	for range int(seconds / w.TimeStep) {
+		if err := w.Step(w.TimeStep); err != nil {
+			t.Fatal(err)
+		}
+		check()
+	}
+}
+
+func TestRopeSwingsFromItsPin(t *testing.T) {
+	rope := NewRope(Point{X: 0, Y: 0}, Point{X: 200, Y: 0}, 21, color.RGBA{})
+	top, end := rope.Nodes[0], rope.Nodes[len(rope.Nodes)-1]
+
+	var lowest float32
+	hang(t, rope, 3, func() {
+		if top.X != 0 || top.Y != 0 {
+			t.Fatalf("pinned node moved to %+v", top.Point)
+		}
+		if d := Vector(end.Point).Length(); !(d < 260) {
+			t.Fatalf("rope stretched to %.1f, want about 200", d)
+		}
+		lowest = max(lowest, end.Y)
+	})
+	if lowest < 180 {
+		t.Errorf("rope should swing down under its pin, lowest end y = %.1f", lowest)
+	}
+	if len(rope.Links()) != 20 {
+		t.Errorf("rope tore under its own weight: %d of 20 links left", len(rope.Links()))
+	}
+}
+
+func TestClothHangsBetweenPins(t *testing.T) {
+	cloth := NewCloth(0, 0, 10, 8, 10, color.RGBA{})
+	cloth.Pin(0, cloth.Nodes[0].Point)
+	cloth.Pin(9, cloth.Nodes[9].Point)
+	if len(cloth.Faces()) != 9*7 {
+		t.Fatalf("got %d faces, want %d", len(cloth.Faces()), 9*7)
+	}
+	hang(t, cloth, 3, func() {})
+
+	bottom := cloth.Node(5, 7)
+	if math.IsNaN(float64(bottom.Y)) || bottom.Y < 70 || bottom.Y > 150 {
+		t.Errorf("cloth should drape below its pins, bottom middle at %+v", bottom.Point)
+	}
+	if len(cloth.Faces()) != 9*7 {
+		t.Errorf("cloth tore under its own weight: %d faces left", len(cloth.Faces()))
+	}
+}
+
+func TestClothTears(t *testing.T) {
+	cloth := NewCloth(0, 0, 6, 6, 10, color.RGBA{})
+	for c := range 6 {
+		cloth.Pin(c, cloth.Node(c, 0).Point)
+	}
+	var torn []*Spring
+	w := New()
+	w.Events.SpringBreak.Subscribe(func(s *Spring) { torn = append(torn, s) })
+	w.Add(cloth)
+	// yank the bottom row away
+	for c := range 6 {
+		cloth.Node(c, 5).Velocity = Vector{Y: 5000}
+	}
+	w.Step(w.TimeStep)
+
+	if len(torn) == 0 {
+		t.Fatal("yanked cloth should tear")
+	}
+	if len(cloth.Faces()) >= 5*5 {
+		t.Errorf("torn cloth should have holes, %d faces left", len(cloth.Faces()))
+	}
+	for _, s := range cloth.Springs {
+		if s.Broken {
+			t.Fatal("torn springs should be removed from the cloth")
+		}
+	}
+}
+
+func TestClothSaveLoad(t *testing.T) {
+	w := New()
+	cloth := NewCloth(10, 10, 4, 3, 10, color.RGBA{G: 255, A: 255})
+	cloth.Pin(3, Point{X: 50, Y: 5})
+	w.Add(cloth)
+	filename := filepath.Join(t.TempDir(), "save.json")
+	if err := w.SaveState(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := New()
+	if err := loaded.LoadState(filename); err != nil {
+		t.Fatal(err)
+	}
+	got, ok := loaded.Objects[0].(*Cloth)
+	if !ok {
+		t.Fatalf("got %T, want *Cloth", loaded.Objects[0])
+	}
+	if got.Cols != 4 || got.Rows != 3 || len(got.Springs) != len(cloth.Springs) || len(got.Faces()) != 3*2 {
+		t.Fatalf("cloth did not round trip: %dx%d, %d springs, %d faces", got.Cols, got.Rows, len(got.Springs), len(got.Faces()))
+	}
+	if pinned := got.Nodes[3]; pinned.Mass != 0 || pinned.Point != (Point{X: 50, Y: 5}) {
+		t.Errorf("pin was not restored: %+v", pinned.Body)
+	}
+}
+
+func TestClothNeedsItsGrid(t *testing.T) {
+	if cloth := NewCloth(0, 0, 0, 0, 10, color.RGBA{}); len(cloth.Nodes) != 1 || cloth.Bounds() == (AABB{}) {
+		t.Errorf("empty cloth got %d nodes, want 1", len(cloth.Nodes))
+	}
+	if rope := NewRope(Point{}, Point{X: 10}, 0, color.RGBA{}); len(rope.Nodes) != 2 {
+		t.Errorf("empty rope got %d nodes, want 2", len(rope.Nodes))
+	}
+
+	for _, save := range []string{
+		`{"type":"Cloth","nodes":[],"cols":0,"rows":0}`,
+		`{"type":"Cloth","nodes":[{"radius":1}],"cols":2,"rows":1}`,
+	} {
+		filename := filepath.Join(t.TempDir(), "save.json")
+		if err := os.WriteFile(filename, []byte(`{"Objects":[`+save+`]}`), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := New().LoadState(filename); err == nil {
+			t.Errorf("loading %s should fail", save)
+		}
+	}
+}
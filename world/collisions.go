@@ -0,0 +1,197 @@
+package world
+
+type Collision struct {
+	Hit    bool
+	Normal Vector  // direction to push objects apart
+	Depth  float32 // penetration depth
+	Point  Vector  // contact point (optional, useful for effects)
+}
+
+// CheckCollision returns every contact between a and b, with normals pointing from a to b, or
+// from the static side for boundaries.
+func CheckCollision(a, b any) Manifold {
+	switch sa := a.(type) {
+	case *CubeBoundary:
+		switch sb := b.(type) {
+		case *Circle:
+			return CircleVsCubeBoundary(sb, sa)
+		case Convex:
+			return ConvexVsCubeBoundary(sb, sa)
+		}
+	case *Boundary:
+		switch sb := b.(type) {
+		case *Circle:
+			return CircleVsBoundary(sb, sa)
+		case Convex:
+			return ConvexVsBoundary(sb, sa)
+		case *Cube:
+			return cubeVsBoundary(sb, sa)
+		}
+	case *Circle:
+		switch sb := b.(type) {
+		case *Circle:
+			return single(CircleVsCircle(sa, sb))
+		case *CubeBoundary:
+			return CircleVsCubeBoundary(sa, sb)
+		case *Boundary:
+			return CircleVsBoundary(sa, sb)
+		case Convex:
+			return single(CircleVsConvex(sa, sb))
+		case *Cube:
+			var m Manifold
+			for _, p := range sb.Points {
+				m.Add(CircleVsCircle(sa, p))
+			}
+			return m
+		}
+	case Convex:
+		switch sb := b.(type) {
+		case *Circle:
+			return single(CircleVsConvex(sb, sa)).Flip()
+		case Convex:
+			return ConvexVsConvex(sa, sb)
+		case *Boundary:
+			return ConvexVsBoundary(sa, sb)
+		case *CubeBoundary:
+			return ConvexVsCubeBoundary(sa, sb)
+		}
+	case *Cube:
+		switch sb := b.(type) {
+		case *Circle:
+			var m Manifold
+			for _, p := range sa.Points {
+				m.Add(CircleVsCircle(p, sb))
+			}
+			return m
+		case *Boundary:
+			return cubeVsBoundary(sa, sb)
+		case *Cube:
+			var m Manifold
+			for _, pa := range sa.Points {
+				for _, pb := range sb.Points {
+					m.Add(CircleVsCircle(pa, pb))
+				}
+			}
+			return m
+		}
+	}
+	return Manifold{}
+}
+
+// cubeVsBoundary collects the contacts of every corner of the cube with the boundary.
+func cubeVsBoundary(c *Cube, r *Boundary) Manifold {
+	var m Manifold
+	for _, p := range c.Points {
+		m = m.Merge(CircleVsBoundary(p, r))
+	}
+	return m
+}
+
+func CircleVsCircle(a, b *Circle) Collision {
+	delta := Vector{X: b.X - a.X, Y: b.Y - a.Y}
+	dist := delta.Length()
+	minDist := a.Radius + b.Radius
+
+	if dist >= minDist {
+		return Collision{}
+	}
+
+	normal := delta.Normalize()
+
+	return Collision{
+		Hit:    true,
+		Normal: normal,
+		Depth:  minDist - dist,
+		Point: Vector{
+			X: a.X + normal.X*a.Radius,
+			Y: a.Y + normal.Y*a.Radius,
+		},
+	}
+}
+
+func CircleVsCubeBoundary(c *Circle, r *CubeBoundary) Manifold {
+	return r.CheckCircleCollision(c)
+}
+
+func CircleVsBoundary(c *Circle, r *Boundary) Manifold {
+	return r.CheckCircleCollision(c)
+}
+
+// Convex is a rigid body with a convex outline, collided with the separating axis theorem.
+type Convex interface {
+	Rigid
+	// Vertices returns the outline in world space.
+	Vertices() []Vector
+	// FaceNormals returns the outward normals of the faces; parallel faces may share one.
+	FaceNormals() []Vector
+}
+
+// CircleVsConvex returns the collision between a circle and a convex body, with the normal
+// pointing from the circle to the body.
+func CircleVsConvex(c *Circle, p Convex) Collision {
+	return circleVsPolygon(Vector{X: c.X, Y: c.Y}, c.Radius, p.Vertices())
+}
+
+// ConvexVsConvex finds the axis of least penetration between two convex bodies with the
+// separating axis theorem and clips the touching edges against each other, so a box lying flat on
+// another gets a contact at each end of the overlap. The normal points from a to b.
+func ConvexVsConvex(a, b Convex) Manifold {
+	return satManifold(a.Vertices(), b.Vertices(), a.FaceNormals(), b.FaceNormals())
+}
+
+// ConvexVsBoundary returns the contacts between a convex body and all of the boundary's lines
+// that block it, with normals pointing from the lines towards the body. A one-sided line blocks
+// the body while its center is on the solid side.
+func ConvexVsBoundary(p Convex, r *Boundary) Manifold {
+	return convexVsLines(p, r.Lines)
+}
+
+// ConvexVsCubeBoundary returns the contacts between a convex body and the walls of the box, with
+// normals pointing in from the walls.
+func ConvexVsCubeBoundary(p Convex, r *CubeBoundary) Manifold {
+	edges := r.GetEdges()
+	return convexVsLines(p, edges[:])
+}
+
+func convexVsLines(p Convex, lines []Line) Manifold {
+	verts, axes := p.Vertices(), p.FaceNormals()
+	var m Manifold
+	for _, line := range lines {
+		if !line.Blocks(p.RigidBody().Point) {
+			continue
+		}
+		segment := []Vector{Vector(line.From), Vector(line.To)}
+		m = m.Merge(satManifold(segment, verts, []Vector{line.Normal()}, axes))
+	}
+	return m
+}
+
+// CircleVsBox returns the collision between a circle and a box, with the normal pointing from the
+// circle to the box.
+func CircleVsBox(c *Circle, b *Box) Collision {
+	return CircleVsConvex(c, b)
+}
+
+// BoxVsBox returns the contacts between two boxes. The normals point from a to b.
+func BoxVsBox(a, b *Box) Manifold {
+	return ConvexVsConvex(a, b)
+}
+
+func floatMod(a float32, b int) float32 {
+	return a - float32(int(a)/b)*float32(b)
+}
+
+func projectCorners(corners []Vector, axis Vector) (min, max float32) {
+	min = corners[0].Dot(axis)
+	max = min
+	for i := 1; i < len(corners); i++ {
+		p := corners[i].Dot(axis)
+		if p < min {
+			min = p
+		}
+		if p > max {
+			max = p
+		}
+	}
+	return
+}
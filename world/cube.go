@@ -1,10 +1,16 @@
-package levels
+package world
 
 import (
+	"encoding/json"
+	"fmt"
 	"image/color"
+)
 
-	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/vector"
+// cubeStiffness keeps the outline as stiff as it was when springs added their full force every tick at 60 TPS.
+// It and cubeDamping are per unit of corner mass, since springs now push in physical units.
+const (
+	cubeStiffness = 60 * 60
+	cubeDamping   = 6
 )
 
 type Cube struct {
@@ -31,10 +37,20 @@ func NewCube(x, y, w, h float32, color color.Color, velocity Vector) *Cube {
 		points[i] = NewCircle(corner.X, corner.Y, 1, color, velocity)
 	}
 
-	springs[0] = NewSpring(points[0], points[1], 1, 1, color)
-	springs[1] = NewSpring(points[1], points[2], 1, 1, color)
-	springs[2] = NewSpring(points[2], points[3], 1, 1, color)
-	springs[3] = NewSpring(points[3], points[0], 1, 1, color)
+	// the corners are joined by springs, and shouldn't bump into each other as well
+	group := NewGroup()
+	for _, p := range points {
+		p.Filter.Group = group
+	}
+
+	mass := points[0].Mass
+	springs[0] = NewSpring(points[0], points[1], cubeStiffness*mass, 1, color)
+	springs[1] = NewSpring(points[1], points[2], cubeStiffness*mass, 1, color)
+	springs[2] = NewSpring(points[2], points[3], cubeStiffness*mass, 1, color)
+	springs[3] = NewSpring(points[3], points[0], cubeStiffness*mass, 1, color)
+	for _, s := range springs {
+		s.Damping = cubeDamping * mass
+	}
 
 	return &Cube{
 		Points:  points,
@@ -44,43 +60,28 @@ func NewCube(x, y, w, h float32, color color.Color, velocity Vector) *Cube {
 	}
 }
 
-func (c *Cube) Draw(s *ebiten.Image) {
-	p := &vector.Path{}
-	p.MoveTo(c.Points[0].X, c.Points[0].Y)
-	p.LineTo(c.Points[1].X, c.Points[1].Y)
-	p.LineTo(c.Points[2].X, c.Points[2].Y)
-	p.LineTo(c.Points[3].X, c.Points[3].Y)
-	p.Close()
-	if c.Filled {
-		vector.FillPath(s, p, &vector.FillOptions{}, &vector.DrawPathOptions{
-			AntiAlias: true,
-		})
-	} else {
-		vector.StrokePath(s, p, &vector.StrokeOptions{
-			Width: 1,
-		}, &vector.DrawPathOptions{
-			AntiAlias: true,
-		})
+func (c *Cube) Bounds() AABB {
+	b := c.Points[0].Bounds()
+	for _, p := range c.Points[1:] {
+		b = b.Union(p.Bounds())
 	}
+	return b
+}
 
-	// draw normals
-	if debug {
-		tLine, _ := normal(c.Springs[0].c1.Point, c.Springs[0].c2.Point)
-		rLine, _ := normal(c.Springs[1].c1.Point, c.Springs[1].c2.Point)
-		bLine, _ := normal(c.Springs[2].c1.Point, c.Springs[2].c2.Point)
-		lLine, _ := normal(c.Springs[3].c1.Point, c.Springs[3].c2.Point)
-
-		vector.StrokeLine(s, lLine.From.X, lLine.From.Y, lLine.To.X, lLine.To.Y, 1, green, true)
-		vector.StrokeLine(s, bLine.From.X, bLine.From.Y, bLine.To.X, bLine.To.Y, 1, green, true)
-		vector.StrokeLine(s, rLine.From.X, rLine.From.Y, rLine.To.X, rLine.To.Y, 1, green, true)
-		vector.StrokeLine(s, tLine.From.X, tLine.From.Y, tLine.To.X, tLine.To.Y, 1, green, true)
+// Parts returns the corners, which collide on their own.
+func (c *Cube) Parts() []Object {
+	parts := make([]Object, len(c.Points))
+	for i, p := range c.Points {
+		parts[i] = p
 	}
+	return parts
 }
 
 func (c *Cube) Update(delta float32) error {
 	for _, p := range c.Points {
-		p.X = p.X + p.Velocity.X
-		p.Y = p.Y + p.Velocity.Y
+		p.LastPosition = p.Point
+		p.X = p.X + p.Velocity.X*delta
+		p.Y = p.Y + p.Velocity.Y*delta
 	}
 	for _, s := range c.Springs {
 		s.Update(delta)
@@ -162,3 +163,56 @@ func (c *Cube) GetLines() [4]Line {
 // 		{-sin, cos}, // perpendicular to left/right edges
 // 	}
 // }
+
+type cubeJSON struct {
+	Type    string           `json:"type"`
+	Corners []*Circle        `json:"corners"`
+	Springs []nodeSpringJSON `json:"springs"`
+	// Group keeps the corners from colliding with each other.
+	Group  int32   `json:"group"`
+	W      float32 `json:"w"`
+	H      float32 `json:"h"`
+	ColorR uint8   `json:"R"`
+	ColorG uint8   `json:"G"`
+	ColorB uint8   `json:"B"`
+	ColorA uint8   `json:"A"`
+	Filled bool    `json:"filled"`
+}
+
+func (c *Cube) MarshalJSON() ([]byte, error) {
+	clr := c.Color.(color.RGBA)
+	return json.Marshal(cubeJSON{
+		Type:    "Cube",
+		Corners: c.Points,
+		Springs: marshalNodeSprings(c.Points, c.Springs),
+		Group:   c.Points[0].Filter.Group,
+		W:       c.W,
+		H:       c.H,
+		ColorR:  clr.R,
+		ColorG:  clr.G,
+		ColorB:  clr.B,
+		ColorA:  clr.A,
+		Filled:  c.Filled,
+	})
+}
+
+func (c *Cube) UnmarshalJSON(data []byte) error {
+	var aux cubeJSON
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	if len(aux.Corners) != 4 {
+		return fmt.Errorf("cube has %d corners, want 4", len(aux.Corners))
+	}
+
+	c.Points = aux.Corners
+	for _, p := range c.Points {
+		p.Filter.Group = aux.Group
+	}
+	reserveGroup(aux.Group)
+	c.Size = Size{W: aux.W, H: aux.H}
+	c.Color = color.RGBA{R: aux.ColorR, G: aux.ColorG, B: aux.ColorB, A: aux.ColorA}
+	c.Springs = unmarshalNodeSprings(c.Points, aux.Springs, c.Color)
+	c.Filled = aux.Filled
+	return nil
+}
@@ -0,0 +1,102 @@
+package world
+
+import "slices"
+
+// Events are what game code can subscribe to as a World steps, for sounds, particles and scoring.
+// Handlers run in the middle of the step, so they mustn't add or remove objects; note what to
+// change and do it once the step is done.
+type Events struct {
+	// BeginContact is published when two objects start touching, PersistContact for every
+	// substep they stay touching and EndContact when they come apart or one is removed.
+	BeginContact   Topic[ContactEvent]
+	PersistContact Topic[ContactEvent]
+	EndContact     Topic[ContactEvent]
+	// SpringBreak is published with every spring that snaps, after it has been removed.
+	SpringBreak Topic[*Spring]
+}
+
+// ContactEvent describes a contact between two objects. A is the static side when there is one,
+// like a Boundary, and Normal points from A to B. Point is the deepest point of contact and
+// Impulse the total normal impulse the solver pushed them apart with this substep; for
+// EndContact they're where the objects last touched.
+type ContactEvent struct {
+	A, B    Object
+	Normal  Vector
+	Point   Vector
+	Impulse float32
+}
+
+// Topic is one kind of event that any number of handlers can subscribe to.
+type Topic[E any] struct {
+	handlers []handler[E]
+	lastID   int
+}
+
+type handler[E any] struct {
+	id int
+	fn func(E)
+}
+
+// Subscribe calls fn with every event published from now on, until unsubscribe is called.
+func (t *Topic[E]) Subscribe(fn func(E)) (unsubscribe func()) {
+	t.lastID++
+	id := t.lastID
+	t.handlers = append(t.handlers, handler[E]{id: id, fn: fn})
+	return func() {
+		t.handlers = slices.DeleteFunc(t.handlers, func(h handler[E]) bool { return h.id == id })
+	}
+}
+
+func (t *Topic[E]) publish(e E) {
+	// a handler may unsubscribe itself
+	for _, h := range slices.Clone(t.handlers) {
+		h.fn(e)
+	}
+}
+
+// publishContacts compares this substep's contacts with the last one's and publishes what began,
+// persisted and ended. Contacts between bodies that have fallen asleep aren't checked any more, so
+// they're carried over as long as both objects are still in the world, without any events.
+func (w *World) publishContacts(contacts []ContactEvent, present []Object) {
+	var inWorld map[Object]bool
+	stillThere := func(o Object) bool {
+		if inWorld == nil {
+			inWorld = make(map[Object]bool, len(present))
+			for _, p := range present {
+				inWorld[p] = true
+			}
+		}
+		return inWorld[o]
+	}
+	key := func(e ContactEvent) [2]Object { return [2]Object{e.A, e.B} }
+	current := make(map[[2]Object]bool, len(contacts))
+	for _, c := range contacts {
+		current[key(c)] = true
+	}
+	last := make(map[[2]Object]bool, len(w.contacts))
+	for _, c := range w.contacts {
+		last[key(c)] = true
+	}
+
+	for _, c := range w.contacts {
+		if current[key(c)] {
+			continue
+		}
+		if resting(c.A) && resting(c.B) && stillThere(c.A) && stillThere(c.B) {
+			contacts = append(contacts, c)
+			continue
+		}
+		w.Events.EndContact.publish(c)
+	}
+	for _, c := range contacts {
+		switch {
+		case !current[key(c)]:
+			// carried over while asleep
+		case last[key(c)]:
+			w.Events.PersistContact.publish(c)
+		default:
+			w.Events.BeginContact.publish(c)
+		}
+	}
+	w.contacts = contacts
+}
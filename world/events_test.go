@@ -0,0 +1,75 @@
+package world
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestContactEvents(t *testing.T) {
+	w := New()
+	w.Gravity = true
+	floor := NewBoundaryLine(Point{X: -100, Y: 100}, Point{X: 100, Y: 100}, 1, color.RGBA{})
+	ball := NewCircle(0, 50, 5, color.RGBA{}, Vector{})
+	w.Add(floor, ball)
+
+	var begins, persists, ends []ContactEvent
+	w.Events.BeginContact.Subscribe(func(e ContactEvent) { begins = append(begins, e) })
+	stop := w.Events.PersistContact.Subscribe(func(e ContactEvent) { persists = append(persists, e) })
+	w.Events.EndContact.Subscribe(func(e ContactEvent) { ends = append(ends, e) })
+
+	for len(begins) == 0 {
+		if err := w.Step(w.TimeStep); err != nil {
+			t.Fatal(err)
+		}
+		if w.CollisionCount > 0 && len(begins) == 0 {
+			t.Fatal("a contact was solved without a begin event")
+		}
+	}
+	hit := begins[0]
+	if hit.A != floor || hit.B != ball {
+		t.Fatalf("begin should be between the floor and the ball, got %T and %T", hit.A, hit.B)
+	}
+	if hit.Normal.Y > -0.99 || hit.Impulse <= 0 || hit.Point.Y < 99 || hit.Point.Y > 101 {
+		t.Errorf("begin should carry an upward normal, an impulse and the point on the floor: %+v", hit)
+	}
+
+	// the ball bounces off, so the contact ends
+	for range 10 {
+		if err := w.Step(w.TimeStep); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(ends) != 1 || ends[0].B != ball {
+		t.Fatalf("bounce should end the contact once, got %d ends", len(ends))
+	}
+
+	// let it settle onto the floor
+	ball.Restitution = 0
+	stop()
+	for range 120 {
+		if err := w.Step(w.TimeStep); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(persists) != 0 {
+		t.Errorf("unsubscribed handler still got %d persist events", len(persists))
+	}
+	if !ball.Sleeping {
+		t.Fatal("resting ball should be asleep")
+	}
+	endsBefore := len(ends)
+	if err := w.Step(w.TimeStep); err != nil {
+		t.Fatal(err)
+	}
+	if len(ends) != endsBefore {
+		t.Error("falling asleep on the floor shouldn't end the contact")
+	}
+
+	w.Objects = w.Objects[:1]
+	if err := w.Step(w.TimeStep); err != nil {
+		t.Fatal(err)
+	}
+	if len(ends) != endsBefore+1 {
+		t.Error("removing the ball should end its contact")
+	}
+}
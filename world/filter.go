@@ -0,0 +1,80 @@
+package world
+
+import "sync/atomic"
+
+const (
+	// CategoryDefault is the category every object starts in.
+	CategoryDefault uint32 = 1
+	// MaskAll collides with every category.
+	MaskAll = ^uint32(0)
+)
+
+// DefaultFilter puts an object in CategoryDefault, colliding with everything.
+var DefaultFilter = Filter{Category: CategoryDefault, Mask: MaskAll}
+
+// Filter decides which objects collide. Category is the bit, or bits, an object belongs to and
+// Mask the categories it collides with, and two objects only collide when each is in the other's
+// Mask. A shared non-zero Group overrides both: objects in the same positive group always collide,
+// and objects in the same negative group never do, like the corners of one Cube.
+type Filter struct {
+	Category uint32 `json:"category"`
+	Mask     uint32 `json:"mask"`
+	Group    int32  `json:"group,omitempty"`
+}
+
+// Collides reports whether objects with filters f and o collide.
+func (f Filter) Collides(o Filter) bool {
+	if f.Group != 0 && f.Group == o.Group {
+		return f.Group > 0
+	}
+	return f.Category&o.Mask != 0 && o.Category&f.Mask != 0
+}
+
+// Filtered is implemented by objects with a collision Filter. Objects without one use
+// DefaultFilter.
+type Filtered interface {
+	CollisionFilter() Filter
+}
+
+func (b *Body) CollisionFilter() Filter {
+	return b.Filter
+}
+
+func (b *Boundary) CollisionFilter() Filter {
+	return b.Filter
+}
+
+func (b *CubeBoundary) CollisionFilter() Filter {
+	return b.Filter
+}
+
+// canCollide checks the filters of a and b; it's done before any narrowphase test.
+func canCollide(a, b Object) bool {
+	return filterOf(a).Collides(filterOf(b))
+}
+
+func filterOf(o Object) Filter {
+	if f, ok := o.(Filtered); ok {
+		return f.CollisionFilter()
+	}
+	return DefaultFilter
+}
+
+// lastGroup is the last group handed out by NewGroup.
+var lastGroup atomic.Int32
+
+// NewGroup returns a negative group no other call has returned, for parts of one object that
+// shouldn't collide with each other.
+func NewGroup() int32 {
+	return lastGroup.Add(-1)
+}
+
+// reserveGroup keeps NewGroup from handing out a group loaded from a save.
+func reserveGroup(group int32) {
+	for {
+		last := lastGroup.Load()
+		if group >= last || lastGroup.CompareAndSwap(last, group) {
+			return
+		}
+	}
+}
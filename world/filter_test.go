@@ -0,0 +1,140 @@
+package world
+
+import (
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFilterCollides(t *testing.T) {
+	player := Filter{Category: 1 << 1, Mask: MaskAll &^ (1 << 2)}
+	ghost := Filter{Category: 1 << 2, Mask: MaskAll}
+	tests := []struct {
+		name string
+		a, b Filter
+		want bool
+	}{
+		{"defaults", DefaultFilter, DefaultFilter, true},
+		{"masked out by one side", player, ghost, false},
+		{"other categories", player, DefaultFilter, true},
+		{"negative group", Filter{Category: 1, Mask: MaskAll, Group: -3}, Filter{Category: 1, Mask: MaskAll, Group: -3}, false},
+		{"positive group beats masks", Filter{Category: 1, Group: 2}, Filter{Category: 1, Group: 2}, true},
+		{"different groups use masks", Filter{Category: 1, Mask: MaskAll, Group: -1}, Filter{Category: 1, Mask: MaskAll, Group: -2}, true},
+	}
+	for _, tt := range tests {
+		if got := tt.a.Collides(tt.b); got != tt.want {
+			t.Errorf("%s: got %t, want %t", tt.name, got, tt.want)
+		}
+		if got := tt.b.Collides(tt.a); got != tt.want {
+			t.Errorf("%s reversed: got %t, want %t", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMaskedOutBodiesPassThrough(t *testing.T) {
+	w := New()
+	a := NewCircle(0, 0, 10, color.RGBA{}, Vector{X: 100})
+	b := NewCircle(50, 0, 10, color.RGBA{}, Vector{X: -100})
+	a.Filter = Filter{Category: 1 << 1, Mask: MaskAll &^ (1 << 2)}
+	b.Filter.Category = 1 << 2
+	w.Add(a, b)
+	for range 30 {
+		if err := w.Step(w.TimeStep); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if a.X < 50 || b.X > 0 {
+		t.Errorf("masked out circles should pass through each other: a at %.1f, b at %.1f", a.X, b.X)
+	}
+	if w.CollisionCount != 0 {
+		t.Errorf("masked out pair was still narrowphase tested: %d collisions", w.CollisionCount)
+	}
+}
+
+func TestCubeCornersShareANegativeGroup(t *testing.T) {
+	c1 := NewCube(0, 0, 10, 10, color.RGBA{}, Vector{})
+	c2 := NewCube(0, 0, 10, 10, color.RGBA{}, Vector{})
+	g := c1.Points[0].Filter.Group
+	if g >= 0 {
+		t.Fatalf("cube corners should be in a negative group, got %d", g)
+	}
+	for _, p := range c1.Points {
+		if p.Filter.Group != g {
+			t.Fatalf("corners of one cube in groups %d and %d", g, p.Filter.Group)
+		}
+	}
+	if !c1.Points[0].Filter.Collides(c2.Points[0].Filter) {
+		t.Error("corners of different cubes should still collide")
+	}
+}
+
+func TestFilterSaveLoad(t *testing.T) {
+	w := New()
+	c := NewCircle(0, 0, 5, color.RGBA{}, Vector{})
+	c.Filter = Filter{Category: 4, Mask: 5, Group: -1000}
+	wall := NewBoundaryLine(Point{}, Point{X: 10}, 1, color.RGBA{})
+	wall.Filter = Filter{Category: 2, Mask: 1}
+	w.Add(c, wall)
+	filename := filepath.Join(t.TempDir(), "save.json")
+	if err := w.SaveState(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := New()
+	if err := loaded.LoadState(filename); err != nil {
+		t.Fatal(err)
+	}
+	if got := loaded.Objects[0].(*Circle).Filter; got != c.Filter {
+		t.Errorf("circle filter %+v, want %+v", got, c.Filter)
+	}
+	if got := loaded.Objects[1].(*Boundary).Filter; got != wall.Filter {
+		t.Errorf("boundary filter %+v, want %+v", got, wall.Filter)
+	}
+	if g := NewGroup(); g >= -1000 {
+		t.Errorf("NewGroup handed out %d, which a loaded object already uses", g)
+	}
+}
+
+func TestCubeSaveLoad(t *testing.T) {
+	w := New()
+	cube := NewCube(10, 20, 30, 40, color.RGBA{B: 255, A: 255}, Vector{X: 1})
+	w.Add(NewBoundaryLine(Point{}, Point{X: 10}, 1, color.RGBA{}), cube, NewCircle(0, 0, 5, color.RGBA{}, Vector{}))
+	filename := filepath.Join(t.TempDir(), "save.json")
+	if err := w.SaveState(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := New()
+	if err := loaded.LoadState(filename); err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Objects) != 3 {
+		t.Fatalf("got %d objects, want 3: whatever came after the cube was dropped", len(loaded.Objects))
+	}
+	got, ok := loaded.Objects[1].(*Cube)
+	if !ok {
+		t.Fatalf("got %T, want *Cube", loaded.Objects[1])
+	}
+	if len(got.Springs) != len(cube.Springs) || got.Size != cube.Size || got.Color != cube.Color {
+		t.Errorf("cube did not round trip: got %+v", *got)
+	}
+	for i, p := range got.Points {
+		if p.Point != cube.Points[i].Point || p.Filter.Group != cube.Points[0].Filter.Group {
+			t.Errorf("corner %d at %+v in group %d, want %+v in group %d", i, p.Point, p.Filter.Group, cube.Points[i].Point, cube.Points[0].Filter.Group)
+		}
+	}
+	if g := NewGroup(); g >= cube.Points[0].Filter.Group {
+		t.Errorf("NewGroup handed out %d, which the loaded cube already uses", g)
+	}
+}
+
+func TestLoadUnknownTypeFails(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "save.json")
+	if err := os.WriteFile(filename, []byte(`{"Objects":[{"type":"Teapot"}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := New().LoadState(filename); err == nil {
+		t.Error("loading an unknown type should fail, not quietly drop it")
+	}
+}
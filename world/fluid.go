@@ -0,0 +1,235 @@
+package world
+
+import (
+	"encoding/json"
+	"image/color"
+	"math"
+)
+
+const (
+	// WaterDensity is denser than DefaultDensity, so bodies float in water.
+	WaterDensity = 0.015
+	// OilDensity is lighter than DefaultDensity, so bodies sink slowly through oil.
+	OilDensity = 0.008
+
+	// waterLinearDrag and waterQuadraticDrag slow a fully submerged body by that fraction of its
+	// velocity every second, plus that fraction of its velocity times its speed in pixels.
+	waterLinearDrag    = 3
+	waterQuadraticDrag = 0.002
+	// oil is much thicker than water
+	oilLinearDrag    = 4
+	oilQuadraticDrag = 0.01
+
+	// surfaceColumn is the width in pixels of each column of the rippling surface.
+	surfaceColumn = 8
+	// surfaceStiffness pulls every column back to the still surface, surfaceSpread makes the
+	// columns tug on their neighbours so ripples travel, and surfaceDamping calms them down.
+	surfaceStiffness = 60
+	surfaceSpread    = 4000
+	surfaceDamping   = 4
+	// surfaceSplash is the fraction of an entering body's vertical speed passed on to the
+	// column it falls in.
+	surfaceSplash = 0.3
+)
+
+// Fluid is a region of water, oil or anything else that holds up the circles in it, including the
+// nodes of a Cube, SoftBody or Cloth. A submerged circle is pushed up by the weight of the fluid it
+// displaces, the fluid's Density times its area under the surface, and slowed by linear and
+// quadratic drag in proportion to how much of it is under. Bodies falling in make the surface
+// ripple; the ripples are only for show, the surface that holds bodies up stays flat.
+type Fluid struct {
+	// Region is the fluid, with its surface along the top.
+	Region AABB
+	// Density is the fluid's mass per square pixel, like DefaultDensity is for bodies.
+	Density float32
+	// LinearDrag and QuadraticDrag are the fraction of a body's velocity the fluid takes away
+	// every second, and that fraction again for every pixel per second of speed.
+	LinearDrag    float32
+	QuadraticDrag float32
+	Color         color.Color
+
+	// ripples are how far each column of the surface is pushed down, and ripplesVelocity how fast
+	// it's moving.
+	ripples         []float32
+	ripplesVelocity []float32
+	// wet are the bodies that were in the fluid last step, so new ones splash.
+	wet map[*Body]bool
+}
+
+// NewWater creates a pool of water with its top left corner at x, y.
+func NewWater(x, y, w, h float32, color color.Color) *Fluid {
+	return NewFluid(x, y, w, h, WaterDensity, waterLinearDrag, waterQuadraticDrag, color)
+}
+
+// NewOil creates a pool of oil with its top left corner at x, y.
+func NewOil(x, y, w, h float32, color color.Color) *Fluid {
+	return NewFluid(x, y, w, h, OilDensity, oilLinearDrag, oilQuadraticDrag, color)
+}
+
+// NewFluid creates a pool of fluid with its top left corner at x, y.
+func NewFluid(x, y, w, h, density, linearDrag, quadraticDrag float32, color color.Color) *Fluid {
+	return &Fluid{
+		Region:        AABB{Min: Point{X: x, Y: y}, Max: Point{X: x + w, Y: y + h}},
+		Density:       density,
+		LinearDrag:    linearDrag,
+		QuadraticDrag: quadraticDrag,
+		Color:         color,
+	}
+}
+
+func (f *Fluid) Bounds() AABB {
+	return f.Region
+}
+
+// Surface returns the points along the rippling surface, from left to right.
+func (f *Fluid) Surface() []Point {
+	f.resetRipples()
+	points := make([]Point, len(f.ripples))
+	step := (f.Region.Max.X - f.Region.Min.X) / float32(len(f.ripples)-1)
+	for i, r := range f.ripples {
+		y := min(f.Region.Min.Y+r, f.Region.Max.Y)
+		points[i] = Point{X: f.Region.Min.X + float32(i)*step, Y: y}
+	}
+	return points
+}
+
+// resetRipples makes a still surface if the region has changed size, or there isn't one yet.
+func (f *Fluid) resetRipples() {
+	columns := max(int((f.Region.Max.X-f.Region.Min.X)/surfaceColumn), 1) + 1
+	if len(f.ripples) != columns {
+		f.ripples = make([]float32, columns)
+		f.ripplesVelocity = make([]float32, columns)
+	}
+}
+
+// Update moves the ripples on the surface.
+func (f *Fluid) Update(delta float32) error {
+	f.resetRipples()
+	for i, r := range f.ripples {
+		left, right := r, r
+		if i > 0 {
+			left = f.ripples[i-1]
+		}
+		if i+1 < len(f.ripples) {
+			right = f.ripples[i+1]
+		}
+		// the spread term is scaled by the column width, so ripples travel as fast in any pool
+		accel := -surfaceStiffness*r + surfaceSpread*(left+right-2*r)/surfaceColumn - surfaceDamping*f.ripplesVelocity[i]
+		f.ripplesVelocity[i] += accel * delta
+	}
+	for i := range f.ripples {
+		f.ripples[i] += f.ripplesVelocity[i] * delta
+	}
+	return nil
+}
+
+// submerged returns the area of c under the fluid's surface, or 0 if it's outside the fluid.
+func (f *Fluid) submerged(c *Circle) float32 {
+	if c.X < f.Region.Min.X || c.X > f.Region.Max.X || c.Y-c.Radius > f.Region.Max.Y {
+		return 0
+	}
+	r := c.Radius
+	depth := min(max(c.Y+r-f.Region.Min.Y, 0), 2*r)
+	if depth == 0 {
+		return 0
+	}
+	// the circular segment below the surface
+	d := r - depth
+	return r*r*float32(math.Acos(float64(d/r))) - d*float32(math.Sqrt(float64(r*r-d*d)))
+}
+
+// push holds up and drags every circle in the fluid, and splashes the ones that just fell in.
+func (f *Fluid) push(objects []Object, gravity, delta float32) {
+	wet := make(map[*Body]bool, len(f.wet))
+	for _, o := range objects {
+		c, ok := o.(*Circle)
+		if !ok || c.Mass == 0 || c.Sleeping {
+			continue
+		}
+		area := f.submerged(c)
+		if area == 0 {
+			continue
+		}
+		b := c.RigidBody()
+		wet[b] = true
+		if !f.wet[b] {
+			f.splash(c.X, c.Velocity.Y)
+		}
+
+		b.Velocity.Y -= f.Density * area * gravity / b.Mass * delta
+		under := area / (math.Pi * c.Radius * c.Radius)
+		speed := b.Velocity.Length()
+		// never slow it down past stopped
+		slow := min((f.LinearDrag+f.QuadraticDrag*speed)*under*delta, 1)
+		b.Velocity = b.Velocity.Scale(1 - slow)
+		b.AngularVelocity *= 1 - slow
+	}
+	f.wet = wet
+}
+
+// splash pushes the column of the surface at x down at speed.
+func (f *Fluid) splash(x, speed float32) {
+	f.resetRipples()
+	i := int((x - f.Region.Min.X) / surfaceColumn)
+	i = min(max(i, 0), len(f.ripples)-1)
+	f.ripplesVelocity[i] += speed * surfaceSplash
+}
+
+// applyFluids has every fluid in the world hold up and drag the circles in it. Without uniform
+// gravity there's nothing to float against, so there's only drag.
+func (w *World) applyFluids(delta float32) {
+	var objects []Object
+	gravity := float32(0)
+	if w.Gravity && !w.NBody {
+		gravity = GravityConstant
+	}
+	for _, o := range w.Objects {
+		if f, ok := o.(*Fluid); ok {
+			if objects == nil {
+				objects = w.colliders()
+			}
+			f.push(objects, gravity, delta)
+		}
+	}
+}
+
+type fluidJSON struct {
+	Type          string  `json:"type"`
+	Region        AABB    `json:"region"`
+	Density       float32 `json:"density"`
+	LinearDrag    float32 `json:"linearDrag"`
+	QuadraticDrag float32 `json:"quadraticDrag"`
+	ColorR        uint8   `json:"R"`
+	ColorG        uint8   `json:"G"`
+	ColorB        uint8   `json:"B"`
+	ColorA        uint8   `json:"A"`
+}
+
+func (f *Fluid) MarshalJSON() ([]byte, error) {
+	c := f.Color.(color.RGBA)
+	return json.Marshal(fluidJSON{
+		Type:          "Fluid",
+		Region:        f.Region,
+		Density:       f.Density,
+		LinearDrag:    f.LinearDrag,
+		QuadraticDrag: f.QuadraticDrag,
+		ColorR:        c.R,
+		ColorG:        c.G,
+		ColorB:        c.B,
+		ColorA:        c.A,
+	})
+}
+
+func (f *Fluid) UnmarshalJSON(data []byte) error {
+	var aux fluidJSON
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	f.Region = aux.Region
+	f.Density = aux.Density
+	f.LinearDrag = aux.LinearDrag
+	f.QuadraticDrag = aux.QuadraticDrag
+	f.Color = color.RGBA{R: aux.ColorR, G: aux.ColorG, B: aux.ColorB, A: aux.ColorA}
+	f.ripples, f.ripplesVelocity, f.wet = nil, nil, nil
+	return nil
+}
@@ -0,0 +1,103 @@
+package world
+
+import (
+	"image/color"
+	"path/filepath"
+	"testing"
+)
+
+func TestCircleFloatsInWater(t *testing.T) {
+	w := New()
+	w.Gravity = true
+	water := NewWater(-100, 0, 200, 300, color.RGBA{})
+	ball := NewCircle(0, -50, 10, color.RGBA{}, Vector{})
+	w.Add(water, ball)
+
+	steps(t, w, 600)
+	// two thirds of it under, as it's two thirds as dense as water
+	if ball.Y < 0 || ball.Y > 10 {
+		t.Errorf("ball floats at %v, want a little below the surface at 0", ball.Y)
+	}
+	if ball.Velocity.Length() > 1 {
+		t.Errorf("drag should have calmed the ball, velocity %+v", ball.Velocity)
+	}
+}
+
+func TestCircleSinksSlowlyInOil(t *testing.T) {
+	w := New()
+	w.Gravity = true
+	oil := NewOil(-100, 0, 200, 1000, color.RGBA{})
+	ball := NewCircle(0, 50, 10, color.RGBA{}, Vector{})
+	free := NewCircle(500, 50, 10, color.RGBA{}, Vector{})
+	w.Add(oil, ball, free)
+
+	steps(t, w, 60)
+	if ball.Velocity.Y <= 0 {
+		t.Errorf("ball should sink in oil, velocity %+v", ball.Velocity)
+	}
+	if ball.Velocity.Y > free.Velocity.Y/4 {
+		t.Errorf("oil should slow the ball down a lot: %v, falling freely %v", ball.Velocity.Y, free.Velocity.Y)
+	}
+}
+
+func TestCubeCornersFloat(t *testing.T) {
+	w := New()
+	w.Gravity = true
+	w.Substeps = 4
+	water := NewWater(-100, 0, 200, 300, color.RGBA{})
+	cube := NewCube(-20, -40, 40, 20, color.RGBA{}, Vector{})
+	w.Add(water, cube)
+
+	steps(t, w, 600)
+	for i, corner := range cube.Points {
+		if corner.Y < -25 || corner.Y > 30 {
+			t.Errorf("corner %d at %v, want the cube floating at the surface", i, corner.Y)
+		}
+	}
+}
+
+func TestSurfaceRipplesOnEntry(t *testing.T) {
+	w := New()
+	w.Gravity = true
+	water := NewWater(-100, 0, 200, 300, color.RGBA{})
+	w.Add(water)
+	for _, p := range water.Surface() {
+		if p.Y != 0 {
+			t.Fatalf("surface should start still, got %+v", p)
+		}
+	}
+
+	w.Add(NewCircle(0, -5, 10, color.RGBA{}, Vector{Y: 300}))
+	steps(t, w, 10)
+	moved := 0
+	for _, p := range water.Surface() {
+		if p.Y != 0 {
+			moved++
+		}
+	}
+	if moved < 3 {
+		t.Errorf("the splash should spread along the surface, %d columns moved", moved)
+	}
+}
+
+func TestFluidSaveLoad(t *testing.T) {
+	w := New()
+	oil := NewOil(10, 20, 30, 40, color.RGBA{R: 40, G: 30, A: 255})
+	w.Add(oil)
+	filename := filepath.Join(t.TempDir(), "save.json")
+	if err := w.SaveState(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := New()
+	if err := loaded.LoadState(filename); err != nil {
+		t.Fatal(err)
+	}
+	got, ok := loaded.Objects[0].(*Fluid)
+	if !ok {
+		t.Fatalf("got %T, want *Fluid", loaded.Objects[0])
+	}
+	if got.Region != oil.Region || got.Density != oil.Density || got.LinearDrag != oil.LinearDrag || got.QuadraticDrag != oil.QuadraticDrag || got.Color != oil.Color {
+		t.Errorf("fluid did not round trip: got %+v, want %+v", *got, *oil)
+	}
+}
@@ -0,0 +1,246 @@
+package world
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"math"
+)
+
+// FieldKind is the way a ForceField pushes.
+type FieldKind int
+
+const (
+	// FieldWind pushes everything in the field the same way, along its Direction.
+	FieldWind FieldKind = iota
+	// FieldAttractor pulls bodies towards its Center; a negative Strength makes it a repulsor.
+	FieldAttractor
+	// FieldVortex swirls bodies around its Center, clockwise on screen for a positive Strength.
+	FieldVortex
+)
+
+func (k FieldKind) String() string {
+	switch k {
+	case FieldWind:
+		return "wind"
+	case FieldAttractor:
+		return "attractor"
+	case FieldVortex:
+		return "vortex"
+	}
+	return fmt.Sprintf("FieldKind(%d)", int(k))
+}
+
+// Falloff is how a field weakens away from its Center.
+type Falloff int
+
+const (
+	// FalloffNone is as strong at the edge as at the center.
+	FalloffNone Falloff = iota
+	// FalloffLinear fades to nothing at the field's Radius.
+	FalloffLinear
+	// FalloffInverseSquare fades with the square of the distance, softened near the center so it
+	// doesn't fling bodies that pass through it.
+	FalloffInverseSquare
+)
+
+func (f Falloff) String() string {
+	switch f {
+	case FalloffNone:
+		return "none"
+	case FalloffLinear:
+		return "linear"
+	case FalloffInverseSquare:
+		return "inverse square"
+	}
+	return fmt.Sprintf("Falloff(%d)", int(f))
+}
+
+const (
+	// fieldSoftening is the distance from the center, in pixels, at which an inverse square
+	// field has fallen to half its Strength.
+	fieldSoftening = 20
+	// vortexInflow is the fraction of a vortex's swirl that also pulls inwards, so bodies circle
+	// the center instead of being flung out of it.
+	vortexInflow = 0.3
+)
+
+// ForceField is a placeable object that accelerates every dynamic body inside it each step, like
+// gravity does, so light and heavy bodies are pushed alike. It includes the parts of composites,
+// like the corners of a Cube, but not immovable or sleeping bodies. It doesn't collide with
+// anything.
+type ForceField struct {
+	Kind FieldKind
+	// Center is what attractors and vortices pull towards and swirl around.
+	Center Point
+	// Direction is the way wind blows; only its direction matters.
+	Direction Vector
+	// Strength is the acceleration in pixels per second squared, at the center for attractors
+	// and vortices.
+	Strength float32
+	// Radius is how far attractors and vortices reach from their Center; zero reaches everywhere.
+	Radius  float32
+	Falloff Falloff
+	// Region bounds the field to a rectangle; the zero Region doesn't.
+	Region AABB
+	Color  color.Color
+}
+
+// NewWind creates a field blowing along direction over region.
+func NewWind(region AABB, direction Vector, strength float32, color color.Color) *ForceField {
+	return &ForceField{Kind: FieldWind, Direction: direction.Normalize(), Strength: strength, Region: region, Color: color}
+}
+
+// NewAttractor creates a field pulling bodies within radius of center towards it, falling off
+// with the square of the distance. A negative strength pushes them away.
+func NewAttractor(center Point, radius, strength float32, color color.Color) *ForceField {
+	return &ForceField{Kind: FieldAttractor, Center: center, Strength: strength, Radius: radius, Falloff: FalloffInverseSquare, Color: color}
+}
+
+// NewVortex creates a field swirling bodies within radius around center, fading out linearly
+// towards the edge.
+func NewVortex(center Point, radius, strength float32, color color.Color) *ForceField {
+	return &ForceField{Kind: FieldVortex, Center: center, Strength: strength, Radius: radius, Falloff: FalloffLinear, Color: color}
+}
+
+// bounded reports whether the field has a Region.
+func (f *ForceField) bounded() bool {
+	return f.Region.Max.X > f.Region.Min.X && f.Region.Max.Y > f.Region.Min.Y
+}
+
+// Contains reports whether p is inside the field's Region and Radius.
+func (f *ForceField) Contains(p Point) bool {
+	if f.bounded() && !f.Region.Overlaps(AABB{Min: p, Max: p}) {
+		return false
+	}
+	if f.Kind != FieldWind && f.Radius > 0 {
+		offset := Vector(p.Sub(f.Center))
+		return offset.Dot(offset) <= f.Radius*f.Radius
+	}
+	return true
+}
+
+// Bounds is the field's Region, or the circle its Radius reaches. A field without either is
+// everywhere.
+func (f *ForceField) Bounds() AABB {
+	switch {
+	case f.bounded():
+		return f.Region
+	case f.Kind != FieldWind && f.Radius > 0:
+		r := Point{X: f.Radius, Y: f.Radius}
+		return AABB{Min: f.Center.Sub(r), Max: f.Center.Add(r)}
+	}
+	return AABB{Min: Point{X: -math.MaxFloat32, Y: -math.MaxFloat32}, Max: Point{X: math.MaxFloat32, Y: math.MaxFloat32}}
+}
+
+// Acceleration returns the acceleration the field gives a body at p.
+func (f *ForceField) Acceleration(p Point) Vector {
+	if !f.Contains(p) {
+		return Vector{}
+	}
+	if f.Kind == FieldWind {
+		return f.Direction.Normalize().Scale(f.Strength)
+	}
+
+	offset := Vector(f.Center.Sub(p))
+	distance := offset.Length()
+	if distance == 0 {
+		return Vector{}
+	}
+	strength := f.Strength
+	switch f.Falloff {
+	case FalloffLinear:
+		if f.Radius > 0 {
+			strength *= 1 - distance/f.Radius
+		}
+	case FalloffInverseSquare:
+		strength *= fieldSoftening * fieldSoftening / (distance*distance + fieldSoftening*fieldSoftening)
+	}
+
+	inwards := offset.Scale(1 / distance)
+	if f.Kind == FieldVortex {
+		// turning the outward offset a quarter turn goes clockwise with y pointing down
+		return inwards.Scale(-1).Perp().Scale(strength).Add(inwards.Scale(float32(math.Abs(float64(strength))) * vortexInflow))
+	}
+	return inwards.Scale(strength)
+}
+
+// Update does nothing; fields don't move.
+func (f *ForceField) Update(delta float32) error {
+	return nil
+}
+
+// ApplyForceFields accelerates every awake dynamic body by the fields it's in.
+func (w *World) ApplyForceFields(delta float32) {
+	var fields []*ForceField
+	for _, o := range w.Objects {
+		if f, ok := o.(*ForceField); ok {
+			fields = append(fields, f)
+		}
+	}
+	if len(fields) == 0 {
+		return
+	}
+	for _, o := range w.colliders() {
+		r, ok := o.(Rigid)
+		if !ok {
+			continue
+		}
+		b := r.RigidBody()
+		if b.Mass == 0 || b.Sleeping {
+			continue
+		}
+		for _, f := range fields {
+			b.Velocity = b.Velocity.Add(f.Acceleration(b.Point).Scale(delta))
+		}
+	}
+}
+
+type forceFieldJSON struct {
+	Type      string  `json:"type"`
+	Kind      int     `json:"kind"`
+	Center    Point   `json:"center"`
+	Direction Vector  `json:"direction"`
+	Strength  float32 `json:"strength"`
+	Radius    float32 `json:"radius"`
+	Falloff   int     `json:"falloff"`
+	Region    AABB    `json:"region"`
+	ColorR    uint8   `json:"R"`
+	ColorG    uint8   `json:"G"`
+	ColorB    uint8   `json:"B"`
+	ColorA    uint8   `json:"A"`
+}
+
+func (f *ForceField) MarshalJSON() ([]byte, error) {
+	c := f.Color.(color.RGBA)
+	return json.Marshal(forceFieldJSON{
+		Type:      "ForceField",
+		Kind:      int(f.Kind),
+		Center:    f.Center,
+		Direction: f.Direction,
+		Strength:  f.Strength,
+		Radius:    f.Radius,
+		Falloff:   int(f.Falloff),
+		Region:    f.Region,
+		ColorR:    c.R,
+		ColorG:    c.G,
+		ColorB:    c.B,
+		ColorA:    c.A,
+	})
+}
+
+func (f *ForceField) UnmarshalJSON(data []byte) error {
+	var aux forceFieldJSON
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	f.Kind = FieldKind(aux.Kind)
+	f.Center = aux.Center
+	f.Direction = aux.Direction
+	f.Strength = aux.Strength
+	f.Radius = aux.Radius
+	f.Falloff = Falloff(aux.Falloff)
+	f.Region = aux.Region
+	f.Color = color.RGBA{R: aux.ColorR, G: aux.ColorG, B: aux.ColorB, A: aux.ColorA}
+	return nil
+}
@@ -0,0 +1,100 @@
+package world
+
+import (
+	"image/color"
+	"path/filepath"
+	"testing"
+)
+
+func TestWindPushesBodiesAndCubeCorners(t *testing.T) {
+	w := New()
+	wind := NewWind(AABB{Min: Point{X: -100, Y: -100}, Max: Point{X: 100, Y: 100}}, Vector{X: 1}, 100, color.RGBA{})
+	ball := NewCircle(0, 0, 5, color.RGBA{}, Vector{})
+	outside := NewCircle(0, 200, 5, color.RGBA{}, Vector{})
+	wall := NewCircle(0, -50, 5, color.RGBA{}, Vector{})
+	wall.Mass = 0
+	cube := NewCube(-20, 20, 20, 20, color.RGBA{}, Vector{})
+	w.Add(wind, ball, outside, wall, cube)
+
+	steps(t, w, 30)
+	// half a second at 100 px/s²
+	if !approx(ball.Velocity.X, 50) || ball.Velocity.Y != 0 {
+		t.Errorf("ball velocity %+v, want 50 px/s to the right", ball.Velocity)
+	}
+	if outside.Velocity != (Vector{}) {
+		t.Errorf("wind reached outside its region: %+v", outside.Velocity)
+	}
+	if wall.Velocity != (Vector{}) {
+		t.Errorf("wind moved an immovable body: %+v", wall.Velocity)
+	}
+	for i, corner := range cube.Points {
+		if corner.Velocity.X < 49 || corner.Velocity.X > 51 {
+			t.Errorf("cube corner %d velocity %+v, want 50 px/s to the right", i, corner.Velocity)
+		}
+	}
+}
+
+func TestAttractorFallsOff(t *testing.T) {
+	center := Point{X: 0, Y: 0}
+	pull := NewAttractor(center, 200, 1000, color.RGBA{})
+	near := pull.Acceleration(Point{X: 20})
+	far := pull.Acceleration(Point{X: 100})
+	if near.X >= 0 || far.X >= 0 {
+		t.Fatalf("attractor should pull towards its center: near %+v, far %+v", near, far)
+	}
+	if !approx(near.X, -500) {
+		t.Errorf("attractor at the softening distance: got %v, want half strength", near.X)
+	}
+	if far.X <= near.X*0.1 {
+		t.Errorf("attractor should be much weaker far away: near %v, far %v", near.X, far.X)
+	}
+	if a := pull.Acceleration(Point{X: 250}); a != (Vector{}) {
+		t.Errorf("attractor reached past its radius: %+v", a)
+	}
+
+	push := NewAttractor(center, 200, -1000, color.RGBA{})
+	if a := push.Acceleration(Point{Y: 20}); a.Y <= 0 {
+		t.Errorf("repulsor should push away from its center: %+v", a)
+	}
+}
+
+func TestVortexSwirlsClockwise(t *testing.T) {
+	w := New()
+	vortex := NewVortex(Point{}, 200, 500, color.RGBA{})
+	ball := NewCircle(100, 0, 5, color.RGBA{}, Vector{})
+	w.Add(vortex, ball)
+
+	steps(t, w, 10)
+	// to the right of the center, clockwise on screen is down, and it's pulled in a little
+	if ball.Velocity.Y <= 0 || ball.Velocity.X >= 0 || ball.Velocity.X < -ball.Velocity.Y {
+		t.Errorf("ball velocity %+v, want mostly down and a little inwards", ball.Velocity)
+	}
+}
+
+func TestForceFieldSaveLoad(t *testing.T) {
+	w := New()
+	wind := NewWind(AABB{Min: Point{X: 10, Y: 20}, Max: Point{X: 30, Y: 40}}, Vector{X: 0, Y: -1}, 300, color.RGBA{B: 255, A: 255})
+	vortex := NewVortex(Point{X: 5, Y: 6}, 70, -80, color.RGBA{R: 255, A: 255})
+	w.Add(wind, vortex)
+	filename := filepath.Join(t.TempDir(), "save.json")
+	if err := w.SaveState(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := New()
+	if err := loaded.LoadState(filename); err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Objects) != 2 {
+		t.Fatalf("loaded %d objects, want 2", len(loaded.Objects))
+	}
+	for i, want := range []*ForceField{wind, vortex} {
+		got, ok := loaded.Objects[i].(*ForceField)
+		if !ok {
+			t.Fatalf("got %T, want *ForceField", loaded.Objects[i])
+		}
+		if *got != *want {
+			t.Errorf("field did not round trip: got %+v, want %+v", *got, *want)
+		}
+	}
+}
@@ -0,0 +1,386 @@
+package world
+
+// Joint is a constraint between two bodies, or between a body and a fixed point in the world. The
+// World solves joints in the same iterations as contacts, so unlike a Spring they hold exactly
+// instead of pulling towards their target.
+type Joint interface {
+	// Bodies returns the joined bodies. The first is nil for a joint anchored to the world.
+	Bodies() (*Body, *Body)
+	// Anchors returns the two attachment points in world space.
+	Anchors() (Point, Point)
+
+	prepare(warmStart bool)
+	solveVelocity()
+	solvePosition()
+}
+
+// jointBase holds the attachments shared by every joint. Anchors are kept in each body's own
+// frame so they turn with it; a nil A is the world, and LocalA is then a point in world space.
+type jointBase struct {
+	A, B   *Body
+	LocalA Vector
+	LocalB Vector
+
+	// ground stands in for a nil A: with no mass it takes impulses without moving.
+	ground Body
+}
+
+func newJointBase(a, b *Body, anchorA, anchorB Point) jointBase {
+	j := jointBase{A: a, B: b}
+	j.LocalA = j.toLocal(j.bodyA(), anchorA)
+	j.LocalB = j.toLocal(b, anchorB)
+	return j
+}
+
+func (j *jointBase) toLocal(b *Body, p Point) Vector {
+	return Vector(p.Sub(b.Point)).Rotate(-b.Angle)
+}
+
+func (j *jointBase) bodyA() *Body {
+	if j.A == nil {
+		return &j.ground
+	}
+	return j.A
+}
+
+func (j *jointBase) Bodies() (*Body, *Body) {
+	return j.A, j.B
+}
+
+func (j *jointBase) Anchors() (Point, Point) {
+	a, _, ra, rb := j.frames()
+	return a.Point.Add(Point(ra)), j.B.Point.Add(Point(rb))
+}
+
+// frames returns body A, standing in the ground for the world, and the offsets of both anchors
+// from their body's center.
+func (j *jointBase) frames() (a *Body, b *Body, ra, rb Vector) {
+	a, b = j.bodyA(), j.B
+	return a, b, j.LocalA.Rotate(a.Angle), j.LocalB.Rotate(b.Angle)
+}
+
+// separation is the offset from anchor A to anchor B.
+func separation(a, b *Body, ra, rb Vector) Vector {
+	return Vector(b.Point.Sub(a.Point)).Add(rb).Sub(ra)
+}
+
+// anchorVelocity is how fast anchor B moves relative to anchor A.
+func anchorVelocity(a, b *Body, ra, rb Vector) Vector {
+	return b.Velocity.Add(rb.Perp().Scale(b.AngularVelocity)).Sub(a.Velocity.Add(ra.Perp().Scale(a.AngularVelocity)))
+}
+
+// push applies impulse at anchor B and the opposite at anchor A.
+func push(a, b *Body, ra, rb, impulse Vector) {
+	a.Velocity = a.Velocity.Sub(impulse.Scale(a.InvMass()))
+	a.AngularVelocity -= ra.Cross(impulse) * a.InvInertia()
+	b.Velocity = b.Velocity.Add(impulse.Scale(b.InvMass()))
+	b.AngularVelocity += rb.Cross(impulse) * b.InvInertia()
+}
+
+// shift is push for positions: it moves and turns the bodies as an impulse would their velocities.
+func shift(a, b *Body, ra, rb, impulse Vector) {
+	a.Point = a.Point.Sub(Point(impulse.Scale(a.InvMass())))
+	a.Angle -= ra.Cross(impulse) * a.InvInertia()
+	b.Point = b.Point.Add(Point(impulse.Scale(b.InvMass())))
+	b.Angle += rb.Cross(impulse) * b.InvInertia()
+}
+
+// axialMass is the effective mass of the anchors along the unit vector u.
+func axialMass(a, b *Body, ra, rb, u Vector) float32 {
+	crA, crB := ra.Cross(u), rb.Cross(u)
+	return inverse(a.InvMass() + b.InvMass() + crA*crA*a.InvInertia() + crB*crB*b.InvInertia())
+}
+
+// mat2 is a 2x2 matrix, for constraints that hold both axes of a point at once.
+type mat2 struct {
+	a, b, c, d float32
+}
+
+// pointMatrix is the effective mass matrix, before inversion, of keeping two anchors together.
+func pointMatrix(a, b *Body, ra, rb Vector) mat2 {
+	mA, mB := a.InvMass(), b.InvMass()
+	iA, iB := a.InvInertia(), b.InvInertia()
+	return mat2{
+		a: mA + mB + iA*ra.Y*ra.Y + iB*rb.Y*rb.Y,
+		b: -iA*ra.X*ra.Y - iB*rb.X*rb.Y,
+		c: -iA*ra.X*ra.Y - iB*rb.X*rb.Y,
+		d: mA + mB + iA*ra.X*ra.X + iB*rb.X*rb.X,
+	}
+}
+
+// solve returns x where m*x = v, or zero if m is singular.
+func (m mat2) solve(v Vector) Vector {
+	det := m.a*m.d - m.b*m.c
+	if det == 0 {
+		return Vector{}
+	}
+	return Vector{X: (m.d*v.X - m.b*v.Y) / det, Y: (m.a*v.Y - m.c*v.X) / det}
+}
+
+// DistanceJoint keeps two anchors exactly Length apart, like a massless rod with a pin at each end.
+type DistanceJoint struct {
+	jointBase
+	Length float32
+
+	u       Vector
+	mass    float32
+	impulse float32
+}
+
+// NewDistanceJoint joins anchorA on a, or the world if a is nil, to anchorB on b at their current
+// distance.
+func NewDistanceJoint(a, b *Body, anchorA, anchorB Point) *DistanceJoint {
+	return &DistanceJoint{
+		jointBase: newJointBase(a, b, anchorA, anchorB),
+		Length:    Vector(anchorB.Sub(anchorA)).Length(),
+	}
+}
+
+func (j *DistanceJoint) prepare(warmStart bool) {
+	a, b, ra, rb := j.frames()
+	j.u = separation(a, b, ra, rb).Normalize()
+	j.mass = axialMass(a, b, ra, rb, j.u)
+	if !warmStart {
+		j.impulse = 0
+	}
+	push(a, b, ra, rb, j.u.Scale(j.impulse))
+}
+
+func (j *DistanceJoint) solveVelocity() {
+	a, b, ra, rb := j.frames()
+	lambda := -j.mass * anchorVelocity(a, b, ra, rb).Dot(j.u)
+	j.impulse += lambda
+	push(a, b, ra, rb, j.u.Scale(lambda))
+}
+
+func (j *DistanceJoint) solvePosition() {
+	a, b, ra, rb := j.frames()
+	d := separation(a, b, ra, rb)
+	u := d.Normalize()
+	c := d.Length() - j.Length
+	shift(a, b, ra, rb, u.Scale(-c*axialMass(a, b, ra, rb, u)))
+}
+
+// RopeJoint stops two anchors getting further apart than MaxLength, but lets them come closer.
+type RopeJoint struct {
+	jointBase
+	MaxLength float32
+
+	u       Vector
+	mass    float32
+	impulse float32
+	taut    bool
+}
+
+// NewRopeJoint ties anchorA on a, or the world if a is nil, to anchorB on b with a rope of
+// maxLength.
+func NewRopeJoint(a, b *Body, anchorA, anchorB Point, maxLength float32) *RopeJoint {
+	return &RopeJoint{
+		jointBase: newJointBase(a, b, anchorA, anchorB),
+		MaxLength: maxLength,
+	}
+}
+
+func (j *RopeJoint) prepare(warmStart bool) {
+	a, b, ra, rb := j.frames()
+	d := separation(a, b, ra, rb)
+	j.taut = d.Length() >= j.MaxLength
+	if !j.taut || !warmStart {
+		j.impulse = 0
+	}
+	j.u = d.Normalize()
+	j.mass = axialMass(a, b, ra, rb, j.u)
+	push(a, b, ra, rb, j.u.Scale(j.impulse))
+}
+
+func (j *RopeJoint) solveVelocity() {
+	if !j.taut {
+		return
+	}
+	a, b, ra, rb := j.frames()
+	lambda := -j.mass * anchorVelocity(a, b, ra, rb).Dot(j.u)
+	// a rope can only pull
+	total := min(j.impulse+lambda, 0)
+	lambda, j.impulse = total-j.impulse, total
+	push(a, b, ra, rb, j.u.Scale(lambda))
+}
+
+func (j *RopeJoint) solvePosition() {
+	a, b, ra, rb := j.frames()
+	d := separation(a, b, ra, rb)
+	c := d.Length() - j.MaxLength
+	if c <= 0 {
+		return
+	}
+	u := d.Normalize()
+	shift(a, b, ra, rb, u.Scale(-c*axialMass(a, b, ra, rb, u)))
+}
+
+// RevoluteJoint pins two bodies together at a point they both turn about, like a hinge or an
+// axle.
+type RevoluteJoint struct {
+	jointBase
+
+	impulse Vector
+}
+
+// NewRevoluteJoint pins b to a, or to the world if a is nil, at anchor.
+func NewRevoluteJoint(a, b *Body, anchor Point) *RevoluteJoint {
+	return &RevoluteJoint{jointBase: newJointBase(a, b, anchor, anchor)}
+}
+
+func (j *RevoluteJoint) prepare(warmStart bool) {
+	a, b, ra, rb := j.frames()
+	if !warmStart {
+		j.impulse = Vector{}
+	}
+	push(a, b, ra, rb, j.impulse)
+}
+
+func (j *RevoluteJoint) solveVelocity() {
+	a, b, ra, rb := j.frames()
+	lambda := pointMatrix(a, b, ra, rb).solve(anchorVelocity(a, b, ra, rb).Scale(-1))
+	j.impulse = j.impulse.Add(lambda)
+	push(a, b, ra, rb, lambda)
+}
+
+func (j *RevoluteJoint) solvePosition() {
+	a, b, ra, rb := j.frames()
+	shift(a, b, ra, rb, pointMatrix(a, b, ra, rb).solve(separation(a, b, ra, rb).Scale(-1)))
+}
+
+// angularMass is the effective mass of turning the bodies against each other.
+func angularMass(a, b *Body) float32 {
+	return inverse(a.InvInertia() + b.InvInertia())
+}
+
+// twist applies an angular impulse to b and the opposite to a.
+func twist(a, b *Body, impulse float32) {
+	a.AngularVelocity -= impulse * a.InvInertia()
+	b.AngularVelocity += impulse * b.InvInertia()
+}
+
+// WeldJoint glues two bodies together at a point, keeping the angle between them too.
+type WeldJoint struct {
+	jointBase
+	// ReferenceAngle is the angle of b relative to a that the joint holds.
+	ReferenceAngle float32
+
+	impulse        Vector
+	angularImpulse float32
+}
+
+// NewWeldJoint glues b to a, or to the world if a is nil, at anchor, as they are now.
+func NewWeldJoint(a, b *Body, anchor Point) *WeldJoint {
+	j := &WeldJoint{jointBase: newJointBase(a, b, anchor, anchor)}
+	j.ReferenceAngle = b.Angle - j.bodyA().Angle
+	return j
+}
+
+func (j *WeldJoint) prepare(warmStart bool) {
+	a, b, ra, rb := j.frames()
+	if !warmStart {
+		j.impulse, j.angularImpulse = Vector{}, 0
+	}
+	push(a, b, ra, rb, j.impulse)
+	twist(a, b, j.angularImpulse)
+}
+
+func (j *WeldJoint) solveVelocity() {
+	a, b, ra, rb := j.frames()
+	angular := -angularMass(a, b) * (b.AngularVelocity - a.AngularVelocity)
+	j.angularImpulse += angular
+	twist(a, b, angular)
+
+	lambda := pointMatrix(a, b, ra, rb).solve(anchorVelocity(a, b, ra, rb).Scale(-1))
+	j.impulse = j.impulse.Add(lambda)
+	push(a, b, ra, rb, lambda)
+}
+
+func (j *WeldJoint) solvePosition() {
+	a, b, _, _ := j.frames()
+	angular := -angularMass(a, b) * (b.Angle - a.Angle - j.ReferenceAngle)
+	a.Angle -= angular * a.InvInertia()
+	b.Angle += angular * b.InvInertia()
+
+	a, b, ra, rb := j.frames()
+	shift(a, b, ra, rb, pointMatrix(a, b, ra, rb).solve(separation(a, b, ra, rb).Scale(-1)))
+}
+
+// PrismaticJoint lets b slide along an axis fixed to a, or to the world, without turning relative
+// to it, like a piston or a bead on a wire.
+type PrismaticJoint struct {
+	jointBase
+	// LocalAxis is the direction of travel in a's frame.
+	LocalAxis      Vector
+	ReferenceAngle float32
+
+	perp           Vector
+	s1, s2         float32
+	mass           float32
+	impulse        float32
+	angularImpulse float32
+}
+
+// NewPrismaticJoint lets b slide through anchor along axis, relative to a or the world if a is nil.
+func NewPrismaticJoint(a, b *Body, anchor Point, axis Vector) *PrismaticJoint {
+	j := &PrismaticJoint{jointBase: newJointBase(a, b, anchor, anchor)}
+	j.LocalAxis = axis.Normalize().Rotate(-j.bodyA().Angle)
+	j.ReferenceAngle = b.Angle - j.bodyA().Angle
+	return j
+}
+
+// perpendicular returns the unit vector across the axis and the lever arms the sideways
+// constraint acts through on each body.
+func (j *PrismaticJoint) perpendicular(a, b *Body, ra, rb Vector) (perp Vector, s1, s2 float32) {
+	perp = j.LocalAxis.Rotate(a.Angle).Perp()
+	d := separation(a, b, ra, rb)
+	return perp, d.Add(ra).Cross(perp), rb.Cross(perp)
+}
+
+// slide applies the sideways impulse along perp with the lever arms s1 and s2.
+func slide(a, b *Body, perp Vector, s1, s2, lambda float32) {
+	a.Velocity = a.Velocity.Sub(perp.Scale(lambda * a.InvMass()))
+	a.AngularVelocity -= lambda * s1 * a.InvInertia()
+	b.Velocity = b.Velocity.Add(perp.Scale(lambda * b.InvMass()))
+	b.AngularVelocity += lambda * s2 * b.InvInertia()
+}
+
+func (j *PrismaticJoint) prepare(warmStart bool) {
+	a, b, ra, rb := j.frames()
+	j.perp, j.s1, j.s2 = j.perpendicular(a, b, ra, rb)
+	j.mass = inverse(a.InvMass() + b.InvMass() + j.s1*j.s1*a.InvInertia() + j.s2*j.s2*b.InvInertia())
+	if !warmStart {
+		j.impulse, j.angularImpulse = 0, 0
+	}
+	slide(a, b, j.perp, j.s1, j.s2, j.impulse)
+	twist(a, b, j.angularImpulse)
+}
+
+func (j *PrismaticJoint) solveVelocity() {
+	a, b := j.bodyA(), j.B
+	angular := -angularMass(a, b) * (b.AngularVelocity - a.AngularVelocity)
+	j.angularImpulse += angular
+	twist(a, b, angular)
+
+	cdot := j.perp.Dot(b.Velocity.Sub(a.Velocity)) + j.s2*b.AngularVelocity - j.s1*a.AngularVelocity
+	lambda := -j.mass * cdot
+	j.impulse += lambda
+	slide(a, b, j.perp, j.s1, j.s2, lambda)
+}
+
+func (j *PrismaticJoint) solvePosition() {
+	a, b, _, _ := j.frames()
+	angular := -angularMass(a, b) * (b.Angle - a.Angle - j.ReferenceAngle)
+	a.Angle -= angular * a.InvInertia()
+	b.Angle += angular * b.InvInertia()
+
+	a, b, ra, rb := j.frames()
+	perp, s1, s2 := j.perpendicular(a, b, ra, rb)
+	c := perp.Dot(separation(a, b, ra, rb))
+	lambda := -c * inverse(a.InvMass()+b.InvMass()+s1*s1*a.InvInertia()+s2*s2*b.InvInertia())
+	a.Point = a.Point.Sub(Point(perp.Scale(lambda * a.InvMass())))
+	a.Angle -= lambda * s1 * a.InvInertia()
+	b.Point = b.Point.Add(Point(perp.Scale(lambda * b.InvMass())))
+	b.Angle += lambda * s2 * b.InvInertia()
+}
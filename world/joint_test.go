@@ -0,0 +1,114 @@
+package world
+
+import (
+	"image/color"
+	"testing"
+)
+
+// runJoints steps a gravity world of the given bodies and joints for seconds, calling check after
+// every step.
+func runJoints(t *testing.T, seconds float32, objects []Object, joints []Joint, check func()) {
+	t.Helper()
+	w := New()
+	w.Gravity = true
+	w.Add(objects...)
+	w.AddJoint(joints...)
+	for range int(seconds / w.TimeStep) {
+		if err := w.Step(w.TimeStep); err != nil {
+			t.Fatal(err)
+		}
+		check()
+	}
+}
+
+func TestDistanceJointPendulum(t *testing.T) {
+	bob := NewCircle(100, 0, 5, color.RGBA{}, Vector{})
+	j := NewDistanceJoint(nil, &bob.Body, Point{X: 0, Y: 0}, bob.Point)
+
+	lowest := bob.Y
+	runJoints(t, 2, []Object{bob}, []Joint{j}, func() {
+		if d := Vector(bob.Point).Length(); d < 99 || d > 101 {
+			t.Fatalf("rod stretched to %.2f, want 100", d)
+		}
+		lowest = max(lowest, bob.Y)
+	})
+	if lowest < 99 {
+		t.Errorf("pendulum should have swung down, lowest y = %.2f", lowest)
+	}
+}
+
+func TestRevoluteJointHinge(t *testing.T) {
+	box := NewBox(0, 0, 40, 10, color.RGBA{}, Vector{})
+	hinge := Point{X: 0, Y: 0} // top left corner
+	j := NewRevoluteJoint(nil, &box.Body, hinge)
+
+	var swing float32
+	runJoints(t, 1, []Object{box}, []Joint{j}, func() {
+		_, anchor := j.Anchors()
+		if d := Vector(anchor.Sub(hinge)).Length(); d > 0.5 {
+			t.Fatalf("hinge pulled %.2f away from its pin", d)
+		}
+		swing = max(swing, box.Angle)
+	})
+	if swing < 1.5 {
+		t.Errorf("box should swing down about its hinge, largest angle = %.2f", swing)
+	}
+}
+
+func TestRopeJointOnlyPulls(t *testing.T) {
+	ball := NewCircle(0, 20, 5, color.RGBA{}, Vector{})
+	j := NewRopeJoint(nil, &ball.Body, Point{X: 0, Y: 0}, ball.Point, 50)
+
+	runJoints(t, 0.1, []Object{ball}, []Joint{j}, func() {})
+	if ball.Velocity.Y <= 0 {
+		t.Fatalf("slack rope should let the ball fall, velocity %+v", ball.Velocity)
+	}
+	runJoints(t, 1, []Object{ball}, []Joint{j}, func() {
+		if ball.Y > 50.5 {
+			t.Fatalf("rope stretched to %.2f, want at most 50", ball.Y)
+		}
+	})
+	if ball.Y < 49 {
+		t.Errorf("ball should hang at the end of the rope, y = %.2f", ball.Y)
+	}
+}
+
+func TestWeldJointHoldsAngle(t *testing.T) {
+	a := NewBox(0, 0, 20, 20, color.RGBA{}, Vector{})
+	b := NewBox(20, 0, 20, 20, color.RGBA{}, Vector{})
+	pin := NewRevoluteJoint(nil, &a.Body, Point{X: 0, Y: 0})
+	weld := NewWeldJoint(&a.Body, &b.Body, Point{X: 20, Y: 10})
+
+	var swing float32
+	runJoints(t, 1, []Object{a, b}, []Joint{pin, weld}, func() {
+		swing = max(swing, a.Angle)
+		if d := b.Angle - a.Angle; d < -0.02 || d > 0.02 {
+			t.Fatalf("welded boxes turned %.3f against each other", d)
+		}
+		anchorA, anchorB := weld.Anchors()
+		if d := Vector(anchorA.Sub(anchorB)).Length(); d > 0.5 {
+			t.Fatalf("weld pulled %.2f apart", d)
+		}
+	})
+	if swing < 1 {
+		t.Errorf("the welded pair should swing about the pin, largest angle = %.2f", swing)
+	}
+}
+
+func TestPrismaticJointSlides(t *testing.T) {
+	box := NewBox(0, 0, 20, 20, color.RGBA{}, Vector{X: 100})
+	box.AngularVelocity = 3
+	j := NewPrismaticJoint(nil, &box.Body, box.Point, Vector{X: 1})
+
+	runJoints(t, 1, []Object{box}, []Joint{j}, func() {
+		if box.Y < 9.5 || box.Y > 10.5 {
+			t.Fatalf("slider left its axis: y = %.2f", box.Y)
+		}
+		if box.Angle < -0.02 || box.Angle > 0.02 {
+			t.Fatalf("slider turned to %.3f", box.Angle)
+		}
+	})
+	if box.X < 100 {
+		t.Errorf("box should keep sliding along the axis, x = %.2f", box.X)
+	}
+}
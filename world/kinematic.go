@@ -0,0 +1,222 @@
+package world
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+)
+
+// Kinematic moves a shape along a scripted Motion, for elevators, spinners and pistons. The shape
+// has no mass, so contacts, gravity and force fields don't move it, but it's given the velocity
+// it moves at every step, so bodies it hits are pushed along and bodies resting on it ride with
+// it. It's a Composite of its one shape, which the World collides like any other body.
+type Kinematic struct {
+	Shape  Rigid
+	Motion Motion
+	// Start is where the shape was, and StartAngle how it was turned, when the motion began.
+	Start      Point
+	StartAngle float32
+	// Time is how long the motion has been going, in seconds.
+	Time float32
+}
+
+// Motion scripts where a kinematic shape is at any time.
+type Motion interface {
+	// Pose returns where a shape that started at start, turned by startAngle, is after t seconds
+	// and how it's turned.
+	Pose(start Point, startAngle, t float32) (Point, float32)
+}
+
+// NewKinematic takes shape out of the hands of the physics and moves it along motion instead,
+// starting from where the motion puts it at time zero.
+func NewKinematic(shape Rigid, motion Motion) *Kinematic {
+	b := shape.RigidBody()
+	b.Mass, b.Inertia = 0, 0
+	b.Velocity, b.AngularVelocity = Vector{}, 0
+	k := &Kinematic{Shape: shape, Motion: motion, Start: b.Point, StartAngle: b.Angle}
+	b.Point, b.Angle = motion.Pose(k.Start, k.StartAngle, 0)
+	b.LastPosition = b.Point
+	return k
+}
+
+func (k *Kinematic) Parts() []Object {
+	return []Object{k.Shape}
+}
+
+func (k *Kinematic) Bounds() AABB {
+	return k.Shape.(Bounded).Bounds()
+}
+
+// Update moves the shape to where its motion has it now, at the velocity that gets it there.
+func (k *Kinematic) Update(delta float32) error {
+	if delta == 0 {
+		return nil
+	}
+	k.Time += delta
+	b := k.Shape.RigidBody()
+	at, angle := k.Motion.Pose(k.Start, k.StartAngle, k.Time)
+	b.Velocity = Vector(at.Sub(b.Point)).Scale(1 / delta)
+	b.AngularVelocity = (angle - b.Angle) / delta
+	if err := k.Shape.Update(delta); err != nil {
+		return err
+	}
+	// don't let rounding drift it off its path
+	b.Point, b.Angle = at, angle
+	return nil
+}
+
+// Waypoints moves along Points at Speed pixels per second, waiting Pause seconds at each one. At
+// the last point it heads back the way it came, or straight to the first point if Loop is set.
+// The shape starts at the first point.
+type Waypoints struct {
+	Points []Point
+	Speed  float32
+	Pause  float32
+	Loop   bool
+}
+
+func (m Waypoints) Pose(start Point, startAngle, t float32) (Point, float32) {
+	if len(m.Points) == 0 {
+		return start, startAngle
+	}
+	route := slices.Clone(m.Points)
+	if !m.Loop {
+		for i := len(m.Points) - 2; i > 0; i-- {
+			route = append(route, m.Points[i])
+		}
+	}
+	if len(route) == 1 || m.Speed <= 0 {
+		return route[0], startAngle
+	}
+
+	legs := make([]float32, len(route))
+	var round float32
+	for i, p := range route {
+		legs[i] = Vector(route[(i+1)%len(route)].Sub(p)).Length() / m.Speed
+		round += m.Pause + legs[i]
+	}
+	t = float32(math.Mod(float64(t), float64(round)))
+	for i, p := range route {
+		if t < m.Pause {
+			return p, startAngle
+		}
+		t -= m.Pause
+		if t < legs[i] {
+			next := route[(i+1)%len(route)]
+			return p.Add(next.Sub(p).Scale(t / legs[i])), startAngle
+		}
+		t -= legs[i]
+	}
+	return route[0], startAngle
+}
+
+// Oscillate swings back and forth through the start, Amplitude away at the furthest, once every
+// Period seconds. Phase shifts it along its swing, in radians.
+type Oscillate struct {
+	Amplitude Vector
+	Period    float32
+	Phase     float32
+}
+
+func (m Oscillate) Pose(start Point, startAngle, t float32) (Point, float32) {
+	if m.Period <= 0 {
+		return start, startAngle
+	}
+	s := float32(math.Sin(float64(2*math.Pi*t/m.Period + m.Phase)))
+	return start.Add(Point(m.Amplitude.Scale(s))), startAngle
+}
+
+// Spin turns around Pivot at AngularVelocity radians per second, carrying the shape around with
+// it if the pivot isn't its center.
+type Spin struct {
+	Pivot           Point
+	AngularVelocity float32
+}
+
+func (m Spin) Pose(start Point, startAngle, t float32) (Point, float32) {
+	turn := m.AngularVelocity * t
+	return start.RotateAround(m.Pivot, turn), startAngle + turn
+}
+
+type kinematicJSON struct {
+	Type       string          `json:"type"`
+	Shape      json.RawMessage `json:"shape"`
+	Motion     motionJSON      `json:"motion"`
+	Start      Point           `json:"start"`
+	StartAngle float32         `json:"startAngle"`
+	Time       float32         `json:"time"`
+}
+
+// motionJSON holds any of the motions, told apart by Kind.
+type motionJSON struct {
+	Kind            string  `json:"kind"`
+	Points          []Point `json:"points,omitempty"`
+	Speed           float32 `json:"speed,omitempty"`
+	Pause           float32 `json:"pause,omitempty"`
+	Loop            bool    `json:"loop,omitempty"`
+	Amplitude       Vector  `json:"amplitude,omitzero"`
+	Period          float32 `json:"period,omitempty"`
+	Phase           float32 `json:"phase,omitempty"`
+	Pivot           Point   `json:"pivot,omitzero"`
+	AngularVelocity float32 `json:"angularVelocity,omitempty"`
+}
+
+func (k *Kinematic) MarshalJSON() ([]byte, error) {
+	shape, err := json.Marshal(k.Shape)
+	if err != nil {
+		return nil, err
+	}
+	var m motionJSON
+	switch motion := k.Motion.(type) {
+	case Waypoints:
+		m = motionJSON{Kind: "Waypoints", Points: motion.Points, Speed: motion.Speed, Pause: motion.Pause, Loop: motion.Loop}
+	case Oscillate:
+		m = motionJSON{Kind: "Oscillate", Amplitude: motion.Amplitude, Period: motion.Period, Phase: motion.Phase}
+	case Spin:
+		m = motionJSON{Kind: "Spin", Pivot: motion.Pivot, AngularVelocity: motion.AngularVelocity}
+	default:
+		return nil, fmt.Errorf("can't save a %T motion", k.Motion)
+	}
+	return json.Marshal(kinematicJSON{
+		Type:       "Kinematic",
+		Shape:      shape,
+		Motion:     m,
+		Start:      k.Start,
+		StartAngle: k.StartAngle,
+		Time:       k.Time,
+	})
+}
+
+func (k *Kinematic) UnmarshalJSON(data []byte) error {
+	var aux kinematicJSON
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	switch aux.Motion.Kind {
+	case "Waypoints":
+		k.Motion = Waypoints{Points: aux.Motion.Points, Speed: aux.Motion.Speed, Pause: aux.Motion.Pause, Loop: aux.Motion.Loop}
+	case "Oscillate":
+		k.Motion = Oscillate{Amplitude: aux.Motion.Amplitude, Period: aux.Motion.Period, Phase: aux.Motion.Phase}
+	case "Spin":
+		k.Motion = Spin{Pivot: aux.Motion.Pivot, AngularVelocity: aux.Motion.AngularVelocity}
+	default:
+		return fmt.Errorf("unknown kinematic motion %q", aux.Motion.Kind)
+	}
+
+	shape, err := unmarshalObject(aux.Shape)
+	if err != nil {
+		return err
+	}
+	switch shape := shape.(type) {
+	case *Circle, *Box, *Polygon:
+		k.Shape = shape.(Rigid)
+	default:
+		return fmt.Errorf("kinematic body has a %T for a shape, want a circle, box or polygon", shape)
+	}
+	k.Start = aux.Start
+	k.StartAngle = aux.StartAngle
+	k.Time = aux.Time
+	return nil
+}
@@ -0,0 +1,152 @@
+package world
+
+import (
+	"image/color"
+	"path/filepath"
+	"testing"
+)
+
+func TestWaypointsPose(t *testing.T) {
+	m := Waypoints{Points: []Point{{X: 0, Y: 0}, {X: 100, Y: 0}, {X: 100, Y: 100}}, Speed: 100, Pause: 1}
+	for _, tc := range []struct {
+		t    float32
+		want Point
+	}{
+		{0.5, Point{X: 0, Y: 0}},       // waiting at the first point
+		{1.5, Point{X: 50, Y: 0}},      // halfway along the first leg
+		{2.5, Point{X: 100, Y: 0}},     // waiting at the second point
+		{3.5, Point{X: 100, Y: 50}},    // halfway down the second leg
+		{5.5, Point{X: 100, Y: 50}},    // and back up it
+		{7.5, Point{X: 50, Y: 0}},      // and back along the first
+		{8.5, Point{X: 0, Y: 0}},       // round again
+		{9.5, Point{X: 50, Y: 0}},      // and off again
+		{-1, Point{X: 0, Y: 0}},        // before it starts
+		{1e6 + 0.5, Point{X: 0, Y: 0}}, // long after
+	} {
+		got, angle := m.Pose(Point{}, 0.3, tc.t)
+		if !approx(got.X, tc.want.X) || !approx(got.Y, tc.want.Y) || angle != 0.3 {
+			t.Errorf("at %vs: got %+v turned %v, want %+v", tc.t, got, angle, tc.want)
+		}
+	}
+}
+
+func TestElevatorCarriesBody(t *testing.T) {
+	w := New()
+	w.Gravity = true
+	platform := NewKinematic(NewBox(-50, 0, 100, 10, color.RGBA{}, Vector{}), Waypoints{
+		Points: []Point{{X: 0, Y: 205}, {X: 0, Y: 105}},
+		Speed:  50,
+		Pause:  1,
+	})
+	rider := NewBox(-10, 180, 20, 20, color.RGBA{}, Vector{})
+	// dead contacts, or the rider bounces off every time the platform sets off
+	platform.Shape.RigidBody().Restitution = 0
+	rider.Restitution = 0
+	w.Add(platform, rider)
+
+	steps(t, w, 60) // settle during the pause
+	steps(t, w, 90) // and rise for a second and a half
+	if !approx(platform.Shape.RigidBody().Y, 130) {
+		t.Fatalf("platform at %v, want 130", platform.Shape.RigidBody().Y)
+	}
+	if got := platform.Shape.RigidBody().Y - rider.Y; got < 12 || got > 17 {
+		t.Errorf("rider is %v above the platform, want it riding on top at 15", got)
+	}
+	if rider.Velocity.Y > -40 {
+		t.Errorf("rider should rise with the platform, velocity %+v", rider.Velocity)
+	}
+}
+
+func TestPausedPlatformKeepsRiderAwake(t *testing.T) {
+	w := New()
+	w.Gravity = true
+	platform := NewKinematic(NewBox(150, 0, 100, 10, color.RGBA{}, Vector{}), Waypoints{
+		Points: []Point{{X: 200, Y: 306}, {X: 200, Y: 506}},
+		Speed:  100,
+		Pause:  2,
+	})
+	rider := NewBox(190, 280, 20, 20, color.RGBA{}, Vector{})
+	platform.Shape.RigidBody().Restitution = 0
+	rider.Restitution = 0
+	w.Add(platform, rider)
+
+	steps(t, w, 110) // long enough to fall asleep during the pause
+	if rider.Sleeping {
+		t.Fatal("rider fell asleep on a platform that's about to move")
+	}
+	steps(t, w, 120) // and down most of the way
+	if got := platform.Shape.RigidBody().Y - rider.Y; got < 12 || got > 17 {
+		t.Errorf("rider is %v above the platform, want it riding down on top at 15", got)
+	}
+}
+
+func TestPistonPushesAndIgnoresForces(t *testing.T) {
+	w := New()
+	w.Gravity = true
+	piston := NewKinematic(NewBox(-20, -20, 20, 40, color.RGBA{}, Vector{}), Oscillate{Amplitude: Vector{X: 40}, Period: 2})
+	ball := NewCircle(25, 0, 5, color.RGBA{}, Vector{})
+	ball.Restitution = 0
+	w.Add(piston, ball)
+
+	steps(t, w, 15) // a quarter of a second into the swing
+	if ball.Velocity.X < 50 {
+		t.Errorf("piston should shove the ball along, velocity %+v", ball.Velocity)
+	}
+	b := piston.Shape.RigidBody()
+	if b.Velocity.Y != 0 || b.Y != 0 {
+		t.Errorf("gravity or the ball moved the piston: at %+v moving %+v", b.Point, b.Velocity)
+	}
+}
+
+func TestSpinnerTurnsAboutPivot(t *testing.T) {
+	w := New()
+	arm := NewKinematic(NewBox(0, -5, 100, 10, color.RGBA{}, Vector{}), Spin{Pivot: Point{}, AngularVelocity: 3.14159265 / 2})
+	w.Add(arm)
+
+	steps(t, w, 60)
+	b := arm.Shape.RigidBody()
+	// a quarter turn clockwise on screen takes the arm's middle from the right to straight down
+	if !approx(b.X, 0) || !approx(b.Y, 50) || !approx(b.Angle, 3.14159265/2) {
+		t.Errorf("arm at %+v turned %v, want at 0, 50 turned a quarter", b.Point, b.Angle)
+	}
+	if !approx(b.AngularVelocity, 3.14159265/2) {
+		t.Errorf("angular velocity %v, want a quarter turn a second", b.AngularVelocity)
+	}
+}
+
+func TestKinematicSaveLoad(t *testing.T) {
+	w := New()
+	for _, m := range []Motion{
+		Waypoints{Points: []Point{{X: 1, Y: 2}, {X: 3, Y: 4}}, Speed: 5, Pause: 6, Loop: true},
+		Oscillate{Amplitude: Vector{X: 7, Y: 8}, Period: 9, Phase: 10},
+		Spin{Pivot: Point{X: 11, Y: 12}, AngularVelocity: 13},
+	} {
+		w.Add(NewKinematic(NewBox(0, 0, 10, 10, color.RGBA{A: 255}, Vector{}), m))
+	}
+	steps(t, w, 10)
+	filename := filepath.Join(t.TempDir(), "save.json")
+	if err := w.SaveState(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := New()
+	if err := loaded.LoadState(filename); err != nil {
+		t.Fatal(err)
+	}
+	for i, o := range w.Objects {
+		want := o.(*Kinematic)
+		got, ok := loaded.Objects[i].(*Kinematic)
+		if !ok {
+			t.Fatalf("got %T, want *Kinematic", loaded.Objects[i])
+		}
+		gb, wb := got.Shape.RigidBody(), want.Shape.RigidBody()
+		if got.Time != want.Time || got.Start != want.Start || gb.Point != wb.Point || gb.Mass != 0 || gb.Inertia != 0 {
+			t.Errorf("kinematic %d did not round trip: got %+v at %+v", i, *got, gb.Point)
+		}
+		if _, ok := got.Motion.(Waypoints); !ok {
+			if got.Motion != want.Motion {
+				t.Errorf("motion %d: got %+v, want %+v", i, got.Motion, want.Motion)
+			}
+		}
+	}
+}
@@ -1,10 +1,56 @@
-package levels
+package world
 
 import "math"
 
 type Line struct {
 	From Point
 	To   Point
+	// Sides says which sides of the line are solid, when it's part of a Boundary.
+	Sides Sides `json:",omitempty"`
+}
+
+// Sides says which sides of a boundary line things collide with.
+type Sides int
+
+const (
+	// TwoSided lines are solid from both sides.
+	TwoSided Sides = iota
+	// OneSided lines only stop things on the side their Normal faces, and let things through
+	// from behind, like a platform that can be jumped up through. Flip the line to face it the
+	// other way.
+	OneSided
+	// PassThrough lines don't stop anything.
+	PassThrough
+)
+
+func (s Sides) String() string {
+	switch s {
+	case TwoSided:
+		return "two-sided"
+	case OneSided:
+		return "one-sided"
+	case PassThrough:
+		return "pass-through"
+	default:
+		return "unknown"
+	}
+}
+
+// Flipped returns the line running the other way, so its Normal faces the other side.
+func (l Line) Flipped() Line {
+	l.From, l.To = l.To, l.From
+	return l
+}
+
+// Blocks reports whether the line stops something coming from p.
+func (l Line) Blocks(p Point) bool {
+	switch l.Sides {
+	case OneSided:
+		return p.Sub(l.From).Dot(l.Normal()) >= 0
+	case PassThrough:
+		return false
+	}
+	return true
 }
 
 func (l Line) Length() float32 {
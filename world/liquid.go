@@ -0,0 +1,453 @@
+package world
+
+import (
+	"encoding/json"
+	"image/color"
+	"math"
+)
+
+const (
+	// DefaultLiquidSmoothing is the distance in pixels over which liquid particles feel each
+	// other, and DefaultLiquidSpacing how far apart they're poured.
+	DefaultLiquidSmoothing = 16
+	DefaultLiquidSpacing   = 6
+	// DefaultLiquidStiffness is how hard particles push apart when they're packed tighter than
+	// they were poured, and DefaultLiquidNearStiffness how hard they keep from bunching up.
+	DefaultLiquidStiffness     = 4000
+	DefaultLiquidNearStiffness = 8000
+	// DefaultLiquidViscosity is how quickly neighbouring particles match their velocities, per
+	// second, and DefaultLiquidQuadraticViscosity that again for every pixel per second they
+	// approach at.
+	DefaultLiquidViscosity          = 4
+	DefaultLiquidQuadraticViscosity = 0.01
+	// DefaultLiquidParticleMass is what each particle weighs when it pushes on bodies, as much
+	// as DefaultDensity gives the square of liquid it stands for.
+	DefaultLiquidParticleMass = DefaultDensity * DefaultLiquidSpacing * DefaultLiquidSpacing
+
+	// maxLiquidStep is the longest step, in seconds, liquids are moved on by at once.
+	maxLiquidStep = 1.0 / 240
+)
+
+// Liquid is a smoothed particle hydrodynamics fluid that can be poured into containers. Each
+// particle's density is summed from its neighbours within Smoothing pixels, found with a
+// SpatialHash of Smoothing sized cells. Particles packed tighter than RestDensity push apart and
+// sparser ones pull together, neighbours drag on each other's velocity to make it viscous, and
+// particles bounce off Boundary lines and push Circles around as if they were small circles of
+// their own, half of Spacing across.
+//
+// It follows Clavet, Beaudoin and Poulin's double density relaxation, which pushes particles
+// apart directly rather than through forces. The World steps it, at most maxLiquidStep at a time
+// so it stays stable; its own Update does nothing.
+type Liquid struct {
+	Particles []*Particle
+	// Smoothing is how far particles reach, and Spacing how far apart Pour places them.
+	Smoothing float32
+	Spacing   float32
+	// RestDensity is the density the liquid settles at. NewLiquid sets it to the density of
+	// particles Spacing apart.
+	RestDensity   float32
+	Stiffness     float32
+	NearStiffness float32
+	// Viscosity and QuadraticViscosity slow down neighbouring particles that approach each other.
+	Viscosity          float32
+	QuadraticViscosity float32
+	// ParticleMass is what a particle weighs against the circles it pushes.
+	ParticleMass float32
+	Filter       Filter
+	Color        color.Color
+
+	grid *SpatialHash
+	// pairs and touching are kept between steps so they don't have to grow again every time.
+	pairs    [][2]*Particle
+	touching []particleContact
+}
+
+// Particle is one drop of a Liquid.
+type Particle struct {
+	Point
+	Velocity Vector
+	// Density is how crowded the particle was last step, RestDensity when the liquid is calm.
+	Density float32
+
+	last Point
+	near float32
+}
+
+// NewLiquid creates a liquid without any particles; Pour some in.
+func NewLiquid(color color.Color) *Liquid {
+	l := &Liquid{
+		Smoothing:          DefaultLiquidSmoothing,
+		Spacing:            DefaultLiquidSpacing,
+		Stiffness:          DefaultLiquidStiffness,
+		NearStiffness:      DefaultLiquidNearStiffness,
+		Viscosity:          DefaultLiquidViscosity,
+		QuadraticViscosity: DefaultLiquidQuadraticViscosity,
+		ParticleMass:       DefaultLiquidParticleMass,
+		Filter:             DefaultFilter,
+		Color:              color,
+	}
+	l.RestDensity = l.packedDensity()
+	return l
+}
+
+// packedDensity is the density of a particle in the middle of particles Spacing apart.
+func (l *Liquid) packedDensity() float32 {
+	var density float32
+	n := int(l.Smoothing / l.Spacing)
+	for x := -n; x <= n; x++ {
+		for y := -n; y <= n; y++ {
+			if x == 0 && y == 0 {
+				continue
+			}
+			d := Vector{X: float32(x) * l.Spacing, Y: float32(y) * l.Spacing}.Length()
+			if q := d / l.Smoothing; q < 1 {
+				density += (1 - q) * (1 - q)
+			}
+		}
+	}
+	return density
+}
+
+// Pour fills the rectangle with its top left corner at x, y with particles Spacing apart.
+func (l *Liquid) Pour(x, y, w, h float32, velocity Vector) {
+	for py := y + l.Spacing/2; py < y+h; py += l.Spacing {
+		for px := x + l.Spacing/2; px < x+w; px += l.Spacing {
+			p := Point{X: px, Y: py}
+			l.Particles = append(l.Particles, &Particle{Point: p, Velocity: velocity, last: p, Density: l.RestDensity})
+		}
+	}
+}
+
+// Radius is how big the particles are when they hit boundaries and bodies.
+func (l *Liquid) Radius() float32 {
+	return l.Spacing / 2
+}
+
+func (l *Liquid) CollisionFilter() Filter {
+	return l.Filter
+}
+
+func (l *Liquid) Bounds() AABB {
+	if len(l.Particles) == 0 {
+		return AABB{}
+	}
+	b := AABB{Min: l.Particles[0].Point, Max: l.Particles[0].Point}
+	for _, p := range l.Particles[1:] {
+		b = b.Union(AABB{Min: p.Point, Max: p.Point})
+	}
+	r := Point{X: l.Radius(), Y: l.Radius()}
+	return AABB{Min: b.Min.Sub(r), Max: b.Max.Add(r)}
+}
+
+// Update does nothing; the World steps liquids itself, as they need the boundaries and bodies
+// around them.
+func (l *Liquid) Update(delta float32) error {
+	return nil
+}
+
+// neighbours finds the pairs of particles close enough to feel each other, and the particles that
+// may be touching one of circles.
+func (l *Liquid) neighbours(circles []*Circle) (particles [][2]*Particle, touching []particleContact) {
+	if l.grid == nil || l.grid.CellSize != l.Smoothing {
+		l.grid = NewSpatialHash(l.Smoothing)
+	}
+	points := make([]Point, len(l.Particles))
+	for i, p := range l.Particles {
+		points[i] = p.Point
+	}
+	l.grid.Index(points)
+	particles, touching = l.pairs[:0], l.touching[:0]
+	l.grid.Within(func(i, j int) {
+		particles = append(particles, [2]*Particle{l.Particles[i], l.Particles[j]})
+	})
+	r := Point{X: l.Radius(), Y: l.Radius()}
+	for _, c := range circles {
+		b := c.Bounds()
+		l.grid.Query(AABB{Min: b.Min.Sub(r), Max: b.Max.Add(r)}, func(i int) {
+			touching = append(touching, particleContact{particle: l.Particles[i], circle: c})
+		})
+	}
+	l.pairs, l.touching = particles, touching
+	return particles, touching
+}
+
+type particleContact struct {
+	particle *Particle
+	circle   *Circle
+}
+
+// step moves the liquid on by delta seconds, pulled down by gravity, inside lines and around
+// circles. wake is called with every circle hit hard enough to wake it up.
+func (l *Liquid) step(delta, gravity float32, lines []Line, circles []*Circle, wake func(Object)) {
+	if len(l.Particles) == 0 || delta == 0 {
+		return
+	}
+	pairs, touching := l.neighbours(circles)
+
+	for _, p := range l.Particles {
+		p.Velocity.Y += gravity * delta
+	}
+	// viscosity: neighbours approaching each other trade some of their velocity
+	for _, pair := range pairs {
+		a, b := pair[0], pair[1]
+		n, d := apart(a, b)
+		q := d / l.Smoothing
+		if q >= 1 {
+			continue
+		}
+		if u := a.Velocity.Sub(b.Velocity).Dot(n); u > 0 {
+			impulse := n.Scale(min(delta*(1-q)*(l.Viscosity*u+l.QuadraticViscosity*u*u), u) / 2)
+			a.Velocity = a.Velocity.Sub(impulse)
+			b.Velocity = b.Velocity.Add(impulse)
+		}
+	}
+
+	for _, p := range l.Particles {
+		p.last = p.Point
+		p.Point = p.Point.Add(Point(p.Velocity.Scale(delta)))
+		p.Density, p.near = 0, 0
+	}
+	l.relax(pairs, delta)
+	for _, p := range l.Particles {
+		p.Velocity = Vector(p.Point.Sub(p.last)).Scale(1 / delta)
+	}
+
+	for _, c := range touching {
+		l.hitCircle(c.particle, c.circle, wake)
+	}
+	for _, p := range l.Particles {
+		l.hitLines(p, lines)
+	}
+}
+
+// relax pushes particles packed tighter than RestDensity apart, and pulls sparser ones together.
+func (l *Liquid) relax(pairs [][2]*Particle, delta float32) {
+	q := make([]float32, len(pairs))
+	for i, pair := range pairs {
+		a, b := pair[0], pair[1]
+		_, d := apart(a, b)
+		q[i] = d / l.Smoothing
+		if q[i] >= 1 {
+			continue
+		}
+		w := 1 - q[i]
+		a.Density += w * w
+		b.Density += w * w
+		a.near += w * w * w
+		b.near += w * w * w
+	}
+	for i, pair := range pairs {
+		if q[i] >= 1 {
+			continue
+		}
+		a, b := pair[0], pair[1]
+		pressure := l.Stiffness * ((a.Density+b.Density)/2 - l.RestDensity)
+		near := l.NearStiffness * (a.near + b.near) / 2
+		w := 1 - q[i]
+		n, _ := apart(a, b)
+		push := n.Scale(delta * delta * (pressure*w + near*w*w) / 2)
+		a.Point = a.Point.Sub(Point(push))
+		b.Point = b.Point.Add(Point(push))
+	}
+}
+
+// minParticleDistance is how close particles can get, in pixels, before they count as on top of
+// each other.
+const minParticleDistance = 0.001
+
+// apart returns the direction from a to b and the distance between them. Particles on top of each
+// other are pushed apart sideways, so they can't stay stuck together.
+func apart(a, b *Particle) (Vector, float32) {
+	offset := Vector(b.Sub(a.Point))
+	d := offset.Length()
+	if d < minParticleDistance {
+		return Vector{X: 1}, 0
+	}
+	return offset.Scale(1 / d), d
+}
+
+// hitCircle pushes p out of c, trading momentum with it as if they were two circles.
+func (l *Liquid) hitCircle(p *Particle, c *Circle, wake func(Object)) {
+	if !canCollide(l, c) {
+		return
+	}
+	offset := Vector(p.Sub(c.Point))
+	d := offset.Length()
+	reach := c.Radius + l.Radius()
+	if d >= reach || d == 0 {
+		return
+	}
+	n := offset.Scale(1 / d)
+	p.Point = c.Point.Add(Point(n.Scale(reach)))
+
+	vn := p.Velocity.Sub(c.Velocity).Dot(n)
+	if vn >= 0 {
+		return
+	}
+	inverseMass := float32(0)
+	if c.Mass > 0 {
+		if c.Sleeping && -vn > sleepingSplash {
+			wake(c)
+		}
+		if !c.Sleeping {
+			inverseMass = 1 / c.Mass
+		}
+	}
+	impulse := -vn / (1/l.ParticleMass + inverseMass)
+	p.Velocity = p.Velocity.Add(n.Scale(impulse / l.ParticleMass))
+	c.Velocity = c.Velocity.Sub(n.Scale(impulse * inverseMass))
+}
+
+// sleepingSplash is how fast, in pixels per second, a particle has to hit a sleeping circle to
+// wake it; liquid resting on a body shouldn't keep it awake.
+const sleepingSplash = 60
+
+// hitLines keeps p from passing through any of lines that block it, and stops it moving into them.
+func (l *Liquid) hitLines(p *Particle, lines []Line) {
+	r := l.Radius()
+	for _, line := range lines {
+		if !line.Blocks(p.last) {
+			continue
+		}
+		if crossing, ok := line.Intersect(Line{From: p.last, To: p.Point}); ok {
+			// it went right through, so put it back on the side it came from
+			n := line.Normal()
+			if Vector(p.last.Sub(crossing)).Dot(n) < 0 {
+				n = n.Scale(-1)
+			}
+			p.Point = crossing.Add(Point(n.Scale(r)))
+			p.Velocity = p.Velocity.Sub(n.Scale(p.Velocity.Dot(n)))
+			continue
+		}
+		closest := Point(line.ClosestPoint(Vector(p.Point)))
+		offset := Vector(p.Sub(closest))
+		d := offset.Length()
+		if d >= r || d == 0 {
+			continue
+		}
+		n := offset.Scale(1 / d)
+		p.Point = closest.Add(Point(n.Scale(r)))
+		if vn := p.Velocity.Dot(n); vn < 0 {
+			p.Velocity = p.Velocity.Sub(n.Scale(vn))
+		}
+	}
+}
+
+// stepLiquids moves every liquid in the world, inside the lines of its boundaries and around its
+// circles.
+func (w *World) stepLiquids(delta float32) {
+	var liquids []*Liquid
+	for _, o := range w.Objects {
+		if l, ok := o.(*Liquid); ok {
+			liquids = append(liquids, l)
+		}
+	}
+	if len(liquids) == 0 {
+		return
+	}
+	gravity := float32(0)
+	if w.Gravity && !w.NBody {
+		gravity = GravityConstant
+	}
+	var circles []*Circle
+	for _, o := range w.colliders() {
+		if c, ok := o.(*Circle); ok {
+			circles = append(circles, c)
+		}
+	}
+	// stiff enough liquids don't stay stable over long steps, so split them up
+	substeps := max(int(math.Ceil(float64(delta/maxLiquidStep)-0.001)), 1)
+	h := delta / float32(substeps)
+	for _, l := range liquids {
+		var lines []Line
+		for _, o := range w.Objects {
+			if !canCollide(l, o) {
+				continue
+			}
+			switch b := o.(type) {
+			case *Boundary:
+				lines = append(lines, b.Lines...)
+			case *CubeBoundary:
+				edges := b.GetEdges()
+				lines = append(lines, edges[:]...)
+			}
+		}
+		for range substeps {
+			l.step(h, gravity, lines, circles, w.Wake)
+		}
+	}
+}
+
+type liquidJSON struct {
+	Type               string         `json:"type"`
+	Particles          []particleJSON `json:"particles"`
+	Smoothing          float32        `json:"smoothing"`
+	Spacing            float32        `json:"spacing"`
+	RestDensity        float32        `json:"restDensity"`
+	Stiffness          float32        `json:"stiffness"`
+	NearStiffness      float32        `json:"nearStiffness"`
+	Viscosity          float32        `json:"viscosity"`
+	QuadraticViscosity float32        `json:"quadraticViscosity"`
+	ParticleMass       float32        `json:"particleMass"`
+	Filter             Filter         `json:"filter"`
+	ColorR             uint8          `json:"R"`
+	ColorG             uint8          `json:"G"`
+	ColorB             uint8          `json:"B"`
+	ColorA             uint8          `json:"A"`
+}
+
+type particleJSON struct {
+	X        float32 `json:"x"`
+	Y        float32 `json:"y"`
+	Velocity Vector  `json:"velocity"`
+}
+
+func (l *Liquid) MarshalJSON() ([]byte, error) {
+	particles := make([]particleJSON, len(l.Particles))
+	for i, p := range l.Particles {
+		particles[i] = particleJSON{X: p.X, Y: p.Y, Velocity: p.Velocity}
+	}
+	c := l.Color.(color.RGBA)
+	return json.Marshal(liquidJSON{
+		Type:               "Liquid",
+		Particles:          particles,
+		Smoothing:          l.Smoothing,
+		Spacing:            l.Spacing,
+		RestDensity:        l.RestDensity,
+		Stiffness:          l.Stiffness,
+		NearStiffness:      l.NearStiffness,
+		Viscosity:          l.Viscosity,
+		QuadraticViscosity: l.QuadraticViscosity,
+		ParticleMass:       l.ParticleMass,
+		Filter:             l.Filter,
+		ColorR:             c.R,
+		ColorG:             c.G,
+		ColorB:             c.B,
+		ColorA:             c.A,
+	})
+}
+
+func (l *Liquid) UnmarshalJSON(data []byte) error {
+	aux := liquidJSON{Filter: DefaultFilter}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	l.Particles = make([]*Particle, len(aux.Particles))
+	for i, p := range aux.Particles {
+		at := Point{X: p.X, Y: p.Y}
+		l.Particles[i] = &Particle{Point: at, Velocity: p.Velocity, last: at, Density: aux.RestDensity}
+	}
+	l.Smoothing = aux.Smoothing
+	l.Spacing = aux.Spacing
+	l.RestDensity = aux.RestDensity
+	l.Stiffness = aux.Stiffness
+	l.NearStiffness = aux.NearStiffness
+	l.Viscosity = aux.Viscosity
+	l.QuadraticViscosity = aux.QuadraticViscosity
+	l.ParticleMass = aux.ParticleMass
+	l.Filter = aux.Filter
+	reserveGroup(aux.Filter.Group)
+	l.Color = color.RGBA{R: aux.ColorR, G: aux.ColorG, B: aux.ColorB, A: aux.ColorA}
+	l.grid = nil
+	return nil
+}
@@ -0,0 +1,92 @@
+package world
+
+import (
+	"image/color"
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+// tank makes a world with a U shaped container 200 wide and 300 deep, open at the top.
+func tank() *World {
+	w := New()
+	w.Gravity = true
+	b := NewBoundaryLine(Point{X: 0, Y: 0}, Point{X: 0, Y: 300}, 2, color.RGBA{})
+	b.Lines = append(b.Lines, Line{From: Point{X: 0, Y: 300}, To: Point{X: 200, Y: 300}}, Line{From: Point{X: 200, Y: 300}, To: Point{X: 200, Y: 0}})
+	w.Add(b)
+	return w
+}
+
+func TestLiquidFillsContainer(t *testing.T) {
+	for _, substeps := range []int{1, 4} {
+		w := tank()
+		w.Substeps = substeps
+		l := NewLiquid(color.RGBA{})
+		l.Pour(50, 50, 100, 100, Vector{})
+		w.Add(l)
+
+		steps(t, w, 300)
+		top := float32(300)
+		for _, p := range l.Particles {
+			if math.IsNaN(float64(p.X)) || p.X < 0 || p.X > 200 || p.Y > 300 {
+				t.Fatalf("%d substeps: particle escaped the tank: %+v", substeps, p.Point)
+			}
+			top = min(top, p.Y)
+		}
+		// 100 by 100 spread over 200 wide is 50 deep
+		if top < 240 || top > 265 {
+			t.Errorf("%d substeps: liquid surface at %v, want it spread out about 50 deep", substeps, top)
+		}
+	}
+}
+
+func TestLiquidPushesCircles(t *testing.T) {
+	w := New()
+	ball := NewCircle(100, 30, 10, color.RGBA{}, Vector{})
+	post := NewCircle(100, 100, 10, color.RGBA{}, Vector{})
+	post.Mass = 0
+	l := NewLiquid(color.RGBA{})
+	l.Pour(0, 0, 60, 130, Vector{X: 200})
+	w.Add(ball, post, l)
+
+	steps(t, w, 60)
+	if ball.Velocity.X <= 0 {
+		t.Errorf("the wave should carry the ball along, velocity %+v", ball.Velocity)
+	}
+	if post.Velocity != (Vector{}) {
+		t.Errorf("an immovable circle was pushed: %+v", post.Velocity)
+	}
+	for _, p := range l.Particles {
+		if d := Vector(p.Sub(post.Point)).Length(); d < post.Radius {
+			t.Fatalf("particle inside the post at %+v", p.Point)
+		}
+	}
+}
+
+func TestLiquidSaveLoad(t *testing.T) {
+	w := New()
+	l := NewLiquid(color.RGBA{B: 255, A: 255})
+	l.Pour(0, 0, 30, 30, Vector{X: 5})
+	w.Add(l)
+	filename := filepath.Join(t.TempDir(), "save.json")
+	if err := w.SaveState(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := New()
+	if err := loaded.LoadState(filename); err != nil {
+		t.Fatal(err)
+	}
+	got, ok := loaded.Objects[0].(*Liquid)
+	if !ok {
+		t.Fatalf("got %T, want *Liquid", loaded.Objects[0])
+	}
+	if len(got.Particles) != len(l.Particles) || got.RestDensity != l.RestDensity || got.Stiffness != l.Stiffness || got.Color != l.Color {
+		t.Fatalf("liquid did not round trip: got %d particles %+v", len(got.Particles), *got)
+	}
+	for i, p := range got.Particles {
+		if p.Point != l.Particles[i].Point || p.Velocity != l.Particles[i].Velocity {
+			t.Errorf("particle %d: got %+v %+v, want %+v %+v", i, p.Point, p.Velocity, l.Particles[i].Point, l.Particles[i].Velocity)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package world
+
+// MaxContacts bounds how many contact points a Manifold keeps for one pair of objects.
+const MaxContacts = 4
+
+// Manifold holds every contact between a pair of objects. Each contact keeps its own normal and
+// depth, because a circle wedged into a corner touches two walls that face different ways, while
+// a box lying flat touches one wall at two points.
+type Manifold struct {
+	Contacts []Collision
+}
+
+// single wraps a lone Collision, which may be a miss, in a Manifold.
+func single(c Collision) Manifold {
+	var m Manifold
+	m.Add(c)
+	return m
+}
+
+func (m Manifold) Hit() bool {
+	return len(m.Contacts) > 0
+}
+
+// Add records c if it's a hit. A contact at the same spot with the same normal as one already
+// held is merged, keeping the deeper of the two, and once MaxContacts is reached the shallowest
+// contact makes way.
+func (m *Manifold) Add(c Collision) {
+	if !c.Hit {
+		return
+	}
+	for i, existing := range m.Contacts {
+		if existing.Normal.Dot(c.Normal) > 0.999 && existing.Point.Sub(c.Point).Length() < 0.5 {
+			if c.Depth > existing.Depth {
+				m.Contacts[i] = c
+			}
+			return
+		}
+	}
+	if len(m.Contacts) < MaxContacts {
+		m.Contacts = append(m.Contacts, c)
+		return
+	}
+	shallowest := 0
+	for i, existing := range m.Contacts {
+		if existing.Depth < m.Contacts[shallowest].Depth {
+			shallowest = i
+		}
+	}
+	if c.Depth > m.Contacts[shallowest].Depth {
+		m.Contacts[shallowest] = c
+	}
+}
+
+// Merge adds every contact of other to m.
+func (m Manifold) Merge(other Manifold) Manifold {
+	for _, c := range other.Contacts {
+		m.Add(c)
+	}
+	return m
+}
+
+// Flip reverses the normals, for when the pair was tested in the opposite order.
+func (m Manifold) Flip() Manifold {
+	flipped := Manifold{Contacts: make([]Collision, len(m.Contacts))}
+	for i, c := range m.Contacts {
+		c.Normal = c.Normal.Scale(-1)
+		flipped.Contacts[i] = c
+	}
+	return flipped
+}
+
+// Deepest returns the contact with the most penetration, or a miss for an empty manifold.
+func (m Manifold) Deepest() Collision {
+	var deepest Collision
+	for _, c := range m.Contacts {
+		if !deepest.Hit || c.Depth > deepest.Depth {
+			deepest = c
+		}
+	}
+	return deepest
+}
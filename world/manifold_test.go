@@ -0,0 +1,69 @@
+package world
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestCircleInCornerTouchesBothWalls(t *testing.T) {
+	walls := NewCubeBoundary(0, 0, 100, 100, 1, color.RGBA{})
+	c := NewCircle(3, 4, 5, color.RGBA{}, Vector{X: -100, Y: -100})
+	m := CheckCollision(c, walls)
+	if len(m.Contacts) != 2 {
+		t.Fatalf("circle in the corner: got %d contacts, want 2: %+v", len(m.Contacts), m)
+	}
+
+	w := New()
+	w.Add(walls, c)
+	steps(t, w, 1)
+	if c.Velocity.X < 0 || c.Velocity.Y < 0 {
+		t.Errorf("circle should bounce off both walls, velocity %+v", c.Velocity)
+	}
+	steps(t, w, 5)
+	if c.X < 5 || c.Y < 5 {
+		t.Errorf("circle should be out of both walls, at %+v", c.Point)
+	}
+}
+
+func TestBoxFlatOnFloorHasTwoContacts(t *testing.T) {
+	floor := NewBoundaryLine(Point{X: -100, Y: 10}, Point{X: 100, Y: 10}, 1, color.RGBA{})
+	box := NewBox(0, 0, 20, 11, color.RGBA{}, Vector{})
+	m := CheckCollision(floor, box)
+	if len(m.Contacts) != 2 {
+		t.Fatalf("box flat on the floor: got %d contacts, want 2: %+v", len(m.Contacts), m)
+	}
+	for _, c := range m.Contacts {
+		if !approx(c.Depth, 1) || c.Normal.Y > -0.99 {
+			t.Errorf("contact %+v: want depth 1 pushing up", c)
+		}
+	}
+
+	other := NewBox(5, 10, 10, 10, color.RGBA{}, Vector{})
+	m = BoxVsBox(box, other)
+	if len(m.Contacts) != 2 {
+		t.Fatalf("box resting on a box: got %d contacts, want 2: %+v", len(m.Contacts), m)
+	}
+	if m.Contacts[0].Point.X == m.Contacts[1].Point.X {
+		t.Errorf("contacts should be at both ends of the overlap: %+v", m)
+	}
+}
+
+func TestManifoldAdd(t *testing.T) {
+	var m Manifold
+	m.Add(Collision{})
+	if m.Hit() {
+		t.Fatal("a miss should not be recorded")
+	}
+	up := Vector{X: 0, Y: -1}
+	m.Add(Collision{Hit: true, Normal: up, Depth: 1, Point: Vector{X: 0, Y: 0}})
+	m.Add(Collision{Hit: true, Normal: up, Depth: 2, Point: Vector{X: 0.1, Y: 0}})
+	if len(m.Contacts) != 1 || m.Contacts[0].Depth != 2 {
+		t.Errorf("contacts at the same spot should merge keeping the deeper: %+v", m)
+	}
+	for i := range MaxContacts + 2 {
+		m.Add(Collision{Hit: true, Normal: up, Depth: float32(i + 3), Point: Vector{X: float32(10 * (i + 1))}})
+	}
+	if len(m.Contacts) != MaxContacts || m.Deepest().Depth != MaxContacts+4 {
+		t.Errorf("a full manifold should keep the deepest %d contacts: %+v", MaxContacts, m)
+	}
+}
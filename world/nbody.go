@@ -0,0 +1,161 @@
+package world
+
+import "math"
+
+const (
+	// DefaultNBodyConstant is the gravitational constant used by New. With it two circles of
+	// radius 20 a hundred pixels apart pull on each other at about a tenth of GravityConstant.
+	DefaultNBodyConstant = 100000
+	// DefaultNBodySoftening is the softening length used by New, in pixels. It keeps the pull
+	// between bodies that pass close to each other from growing without bound.
+	DefaultNBodySoftening = 10
+
+	// barnesHutTheta is how small a quadtree cell has to look from a body, its size over its
+	// distance, before its bodies are pulled towards as one. Smaller is slower but more accurate.
+	barnesHutTheta = 0.5
+	// quadTreeDepth limits how often a cell is split, so bodies on top of each other don't split
+	// it forever. Bodies that end up sharing a leaf don't pull on each other.
+	quadTreeDepth = 32
+)
+
+// quadTree is a Barnes–Hut tree over the bodies that attract each other. Every cell knows the
+// total mass and center of mass of the bodies inside it, so a cluster that is far enough away
+// pulls like a single body and each body's acceleration takes O(log n) instead of O(n).
+type quadTree struct {
+	nodes []quadNode
+}
+
+type quadNode struct {
+	center Point
+	half   float32
+	mass   float32
+	// moment is the mass weighted sum of the positions inside; divided by mass it's the center of
+	// mass.
+	moment Point
+	// body is the only body in a leaf; the first of them if the leaf is at quadTreeDepth.
+	body *Body
+	// children are indices into nodes, with 0 meaning the node is a leaf; the root is never
+	// anyone's child.
+	children [4]int32
+}
+
+func (n *quadNode) leaf() bool {
+	return n.children[0] == 0
+}
+
+// newQuadTree builds a tree over bodies, all of which must have a mass.
+func newQuadTree(bodies []*Body) *quadTree {
+	t := &quadTree{nodes: make([]quadNode, 1, 2*len(bodies)+1)}
+	if len(bodies) == 0 {
+		return t
+	}
+	bounds := AABB{Min: bodies[0].Point, Max: bodies[0].Point}
+	for _, b := range bodies[1:] {
+		bounds = bounds.Union(AABB{Min: b.Point, Max: b.Point})
+	}
+	t.nodes[0].center = bounds.Min.Add(bounds.Max).Scale(0.5)
+	t.nodes[0].half = max(bounds.Max.X-bounds.Min.X, bounds.Max.Y-bounds.Min.Y)/2 + 1
+	for _, b := range bodies {
+		t.insert(0, b, 0)
+	}
+	return t
+}
+
+func (t *quadTree) insert(n int32, b *Body, depth int) {
+	node := &t.nodes[n]
+	node.mass += b.Mass
+	node.moment = node.moment.Add(b.Point.Scale(b.Mass))
+	if node.leaf() {
+		if node.body == nil {
+			node.body = b
+			return
+		}
+		if depth >= quadTreeDepth {
+			return
+		}
+		// split, and move the body that was here down into its quadrant
+		old := node.body
+		node.body = nil
+		t.split(n)
+		t.insert(t.quadrant(n, old.Point), old, depth+1)
+	}
+	t.insert(t.quadrant(n, b.Point), b, depth+1)
+}
+
+// split gives node n four children. It appends to nodes, so pointers into it don't survive.
+func (t *quadTree) split(n int32) {
+	half := t.nodes[n].half / 2
+	center := t.nodes[n].center
+	for i := range 4 {
+		offset := Point{X: -half, Y: -half}
+		if i&1 != 0 {
+			offset.X = half
+		}
+		if i&2 != 0 {
+			offset.Y = half
+		}
+		t.nodes[n].children[i] = int32(len(t.nodes))
+		t.nodes = append(t.nodes, quadNode{center: center.Add(offset), half: half})
+	}
+}
+
+// quadrant returns the child of node n that p falls in.
+func (t *quadTree) quadrant(n int32, p Point) int32 {
+	node := &t.nodes[n]
+	i := 0
+	if p.X >= node.center.X {
+		i |= 1
+	}
+	if p.Y >= node.center.Y {
+		i |= 2
+	}
+	return node.children[i]
+}
+
+// acceleration returns the pull of every other body in the tree on b, for gravitational constant
+// g and softening length softening.
+func (t *quadTree) acceleration(b *Body, g, softening float32) Vector {
+	var a Vector
+	t.accumulate(0, b, g, softening*softening, &a)
+	return a
+}
+
+func (t *quadTree) accumulate(n int32, b *Body, g, softening2 float32, a *Vector) {
+	node := &t.nodes[n]
+	if node.mass == 0 || (node.leaf() && node.body == b) {
+		return
+	}
+	com := node.moment.Scale(1 / node.mass)
+	offset := Vector(com.Sub(b.Point))
+	distance2 := offset.Dot(offset)
+	size := 2 * node.half
+	if !node.leaf() && size*size >= barnesHutTheta*barnesHutTheta*distance2 {
+		// too close to treat as one body
+		for _, child := range node.children {
+			t.accumulate(child, b, g, softening2, a)
+		}
+		return
+	}
+	soft := distance2 + softening2
+	*a = a.Add(offset.Scale(g * node.mass / (soft * float32(math.Sqrt(float64(soft))))))
+}
+
+// applyNBodyGravity pulls every awake circle towards every other one by their masses. Sleeping
+// circles still pull on the others, and immovable ones do neither.
+func (w *World) applyNBodyGravity(delta float32) {
+	var bodies []*Body
+	for _, o := range w.colliders() {
+		if c, ok := o.(*Circle); ok && c.Mass > 0 {
+			bodies = append(bodies, c.RigidBody())
+		}
+	}
+	if len(bodies) < 2 {
+		return
+	}
+	t := newQuadTree(bodies)
+	for _, b := range bodies {
+		if !b.Sleeping {
+			b.Velocity = b.Velocity.Add(t.acceleration(b, w.NBodyConstant, w.NBodySoftening).Scale(delta))
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package world
+
+import (
+	"image/color"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// pullOn sums the pull of every other body on b one pair at a time.
+func pullOn(b *Body, bodies []*Body, g, softening float32) Vector {
+	var a Vector
+	for _, o := range bodies {
+		if o == b {
+			continue
+		}
+		offset := Vector(o.Point.Sub(b.Point))
+		soft := offset.Dot(offset) + softening*softening
+		a = a.Add(offset.Scale(g * o.Mass / (soft * float32(math.Sqrt(float64(soft))))))
+	}
+	return a
+}
+
+func TestBarnesHutMatchesDirectSum(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	var bodies []*Body
+	for range 2000 {
+		c := NewCircle(r.Float32()*1000, r.Float32()*1000, 1+r.Float32()*10, color.RGBA{}, Vector{})
+		bodies = append(bodies, c.RigidBody())
+	}
+	tree := newQuadTree(bodies)
+	for _, b := range bodies[:50] {
+		want := pullOn(b, bodies, DefaultNBodyConstant, DefaultNBodySoftening)
+		got := tree.acceleration(b, DefaultNBodyConstant, DefaultNBodySoftening)
+		if err := got.Sub(want).Length(); err > want.Length()*0.05 {
+			t.Errorf("acceleration %+v, want %+v within 5%%", got, want)
+		}
+	}
+}
+
+func TestNBodyPullsCirclesTogether(t *testing.T) {
+	w := New()
+	w.NBody = true
+	w.Gravity = true // NBody takes its place
+	small := NewCircle(0, 0, 5, color.RGBA{}, Vector{})
+	big := NewCircle(200, 0, 20, color.RGBA{}, Vector{})
+	w.Add(small, big)
+
+	steps(t, w, 10)
+	if small.Velocity.X <= 0 || big.Velocity.X >= 0 {
+		t.Fatalf("circles should fall towards each other: %+v %+v", small.Velocity, big.Velocity)
+	}
+	if small.Velocity.Y != 0 || big.Velocity.Y != 0 {
+		t.Errorf("uniform gravity applied alongside NBody: %+v %+v", small.Velocity, big.Velocity)
+	}
+	momentum := small.Velocity.Scale(small.Mass).Add(big.Velocity.Scale(big.Mass))
+	if momentum.Length() > 0.001*small.Mass*small.Velocity.Length() {
+		t.Errorf("pull should be equal and opposite, momentum %+v", momentum)
+	}
+}
+
+func TestNBodySofteningLimitsPull(t *testing.T) {
+	a := NewCircle(0, 0, 5, color.RGBA{}, Vector{})
+	b := NewCircle(0.01, 0, 5, color.RGBA{}, Vector{})
+	bodies := []*Body{a.RigidBody(), b.RigidBody()}
+	got := newQuadTree(bodies).acceleration(a.RigidBody(), DefaultNBodyConstant, DefaultNBodySoftening)
+	if got.Length() > DefaultNBodyConstant*b.Mass*0.01/(DefaultNBodySoftening*DefaultNBodySoftening*DefaultNBodySoftening) {
+		t.Errorf("pull %v between overlapping circles should be softened", got.Length())
+	}
+	same := NewCircle(0, 0, 5, color.RGBA{}, Vector{})
+	bodies = append(bodies, same.RigidBody())
+	if got := newQuadTree(bodies).acceleration(a.RigidBody(), DefaultNBodyConstant, DefaultNBodySoftening); math.IsNaN(float64(got.X)) {
+		t.Errorf("circles on top of each other give %+v", got)
+	}
+}
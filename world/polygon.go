@@ -0,0 +1,243 @@
+package world
+
+import (
+	"encoding/json"
+	"image/color"
+	"math"
+	"slices"
+)
+
+// Polygon is a rigid convex polygon, like a triangle or a hexagon. Its vertices are kept relative
+// to the center of mass and turned by the body's Angle.
+type Polygon struct {
+	Body
+	// Local holds the vertices around the center of mass, before rotation.
+	Local  []Vector
+	Color  color.Color
+	Filled bool
+}
+
+// NewPolygon creates a polygon from the convex hull of points, which are in world space. It
+// returns nil if the points don't enclose any area.
+func NewPolygon(points []Point, color color.Color, velocity Vector) *Polygon {
+	hull := convexHull(points)
+	if len(hull) < 3 {
+		return nil
+	}
+	area, center := polygonAreaCentroid(hull)
+	if area == 0 {
+		return nil
+	}
+	local := make([]Vector, len(hull))
+	for i, v := range hull {
+		local[i] = v.Sub(center)
+	}
+
+	p := &Polygon{
+		Body:  newBody(center.X, center.Y, velocity),
+		Local: local,
+		Color: color,
+	}
+	p.Mass = DefaultDensity * area
+	p.Inertia = p.defaultInertia()
+	return p
+}
+
+// NewRegularPolygon creates a polygon with the given number of equal sides, centered at x, y with
+// its corners radius away.
+func NewRegularPolygon(x, y, radius float32, sides int, color color.Color, velocity Vector) *Polygon {
+	points := make([]Point, sides)
+	for i := range points {
+		sin, cos := math.Sincos(2 * math.Pi * float64(i) / float64(sides))
+		points[i] = Point{X: x + radius*float32(cos), Y: y + radius*float32(sin)}
+	}
+	return NewPolygon(points, color, velocity)
+}
+
+// defaultInertia is that of a solid polygon with the body's mass spread evenly over its area.
+func (p *Polygon) defaultInertia() float32 {
+	var num, den float32
+	for i, a := range p.Local {
+		b := p.Local[(i+1)%len(p.Local)]
+		cross := float32(math.Abs(float64(a.Cross(b))))
+		num += cross * (a.Dot(a) + a.Dot(b) + b.Dot(b))
+		den += cross
+	}
+	if den == 0 {
+		return 0
+	}
+	return p.Mass * num / (6 * den)
+}
+
+// Vertices returns the corners in world space.
+func (p *Polygon) Vertices() []Vector {
+	sin, cos := float32(math.Sin(float64(p.Angle))), float32(math.Cos(float64(p.Angle)))
+	verts := make([]Vector, len(p.Local))
+	for i, v := range p.Local {
+		verts[i] = Vector{X: p.X + v.X*cos - v.Y*sin, Y: p.Y + v.X*sin + v.Y*cos}
+	}
+	return verts
+}
+
+// FaceNormals returns the outward normal of every edge.
+func (p *Polygon) FaceNormals() []Vector {
+	return polygonAxes(p.Vertices())
+}
+
+func (p *Polygon) Bounds() AABB {
+	verts := p.Vertices()
+	b := AABB{Min: Point(verts[0]), Max: Point(verts[0])}
+	for _, v := range verts[1:] {
+		b = b.Union(AABB{Min: Point(v), Max: Point(v)})
+	}
+	return b
+}
+
+func (p *Polygon) Update(delta float32) error {
+	p.integrate(delta)
+	return nil
+}
+
+// polygonAxes returns the outward unit normals of a convex polygon's edges, whatever its winding.
+func polygonAxes(verts []Vector) []Vector {
+	c := centroid(verts)
+	axes := make([]Vector, len(verts))
+	for i, v := range verts {
+		n := Line{From: Point(v), To: Point(verts[(i+1)%len(verts)])}.Normal()
+		if n.Dot(v.Sub(c)) < 0 {
+			n = n.Scale(-1)
+		}
+		axes[i] = n
+	}
+	return axes
+}
+
+// polygonAreaCentroid returns the area and the center of mass of a simple polygon.
+func polygonAreaCentroid(verts []Vector) (float32, Vector) {
+	var area float32
+	var center Vector
+	for i, a := range verts {
+		b := verts[(i+1)%len(verts)]
+		cross := a.Cross(b)
+		area += cross
+		center = center.Add(a.Add(b).Scale(cross))
+	}
+	if area == 0 {
+		return 0, centroid(verts)
+	}
+	center = center.Scale(1 / (3 * area))
+	return float32(math.Abs(float64(area))) / 2, center
+}
+
+// convexHull returns the convex hull of points with Andrew's monotone chain, dropping any points
+// inside or on the edges.
+func convexHull(points []Point) []Vector {
+	pts := make([]Vector, len(points))
+	for i, p := range points {
+		pts[i] = Vector(p)
+	}
+	slices.SortFunc(pts, func(a, b Vector) int {
+		if a.X != b.X {
+			if a.X < b.X {
+				return -1
+			}
+			return 1
+		}
+		if a.Y < b.Y {
+			return -1
+		}
+		if a.Y > b.Y {
+			return 1
+		}
+		return 0
+	})
+	pts = slices.Compact(pts)
+	if len(pts) < 3 {
+		return pts
+	}
+
+	turn := func(o, a, b Vector) float32 {
+		return a.Sub(o).Cross(b.Sub(o))
+	}
+	hull := make([]Vector, 0, 2*len(pts))
+	for _, p := range pts {
+		for len(hull) >= 2 && turn(hull[len(hull)-2], hull[len(hull)-1], p) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, p)
+	}
+	lower := len(hull) + 1
+	for i := len(pts) - 2; i >= 0; i-- {
+		p := pts[i]
+		for len(hull) >= lower && turn(hull[len(hull)-2], hull[len(hull)-1], p) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, p)
+	}
+	return hull[:len(hull)-1]
+}
+
+type polygonJSON struct {
+	Type            string   `json:"type"`
+	Point           Point    `json:"point"`
+	Vertices        []Vector `json:"vertices"`
+	Angle           float32  `json:"angle"`
+	ColorR          uint8    `json:"R"`
+	ColorG          uint8    `json:"G"`
+	ColorB          uint8    `json:"B"`
+	ColorA          uint8    `json:"A"`
+	Filled          bool     `json:"filled"`
+	Velocity        Vector   `json:"velocity"`
+	AngularVelocity float32  `json:"angularVelocity"`
+	Mass            float32  `json:"mass"`
+	Inertia         float32  `json:"inertia"`
+	Restitution     float32  `json:"restitution"`
+	Friction        float32  `json:"friction"`
+	Filter          Filter   `json:"filter"`
+}
+
+func (p *Polygon) MarshalJSON() ([]byte, error) {
+	c := p.Color.(color.RGBA)
+	return json.Marshal(polygonJSON{
+		Type:            "Polygon",
+		Point:           p.Point,
+		Vertices:        p.Local,
+		Angle:           p.Angle,
+		ColorR:          c.R,
+		ColorG:          c.G,
+		ColorB:          c.B,
+		ColorA:          c.A,
+		Filled:          p.Filled,
+		Velocity:        p.Velocity,
+		AngularVelocity: p.AngularVelocity,
+		Mass:            p.Mass,
+		Inertia:         p.Inertia,
+		Restitution:     p.Restitution,
+		Friction:        p.Friction,
+		Filter:          p.Filter,
+	})
+}
+
+func (p *Polygon) UnmarshalJSON(data []byte) error {
+	// saves from before filters existed collide with everything
+	aux := polygonJSON{Filter: DefaultFilter}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	p.Point = aux.Point
+	p.LastPosition = aux.Point
+	p.Local = aux.Vertices
+	p.Angle = aux.Angle
+	p.Color = color.RGBA{R: aux.ColorR, G: aux.ColorG, B: aux.ColorB, A: aux.ColorA}
+	p.Filled = aux.Filled
+	p.Velocity = aux.Velocity
+	p.AngularVelocity = aux.AngularVelocity
+	p.Mass = aux.Mass
+	p.Inertia = aux.Inertia
+	p.Restitution = aux.Restitution
+	p.Friction = aux.Friction
+	p.Filter = aux.Filter
+	reserveGroup(aux.Filter.Group)
+	return nil
+}
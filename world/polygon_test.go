@@ -0,0 +1,50 @@
+package world
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestNewPolygon(t *testing.T) {
+	// the middle point is inside the square and gets dropped by the hull
+	p := NewPolygon([]Point{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 5, Y: 5}, {X: 10, Y: 10}, {X: 0, Y: 10}}, color.RGBA{}, Vector{})
+	if len(p.Local) != 4 {
+		t.Fatalf("got %d vertices, want 4", len(p.Local))
+	}
+	if p.Point != (Point{X: 5, Y: 5}) {
+		t.Errorf("center of mass: got %+v, want {5 5}", p.Point)
+	}
+	box := NewBox(0, 0, 10, 10, color.RGBA{}, Vector{})
+	if !approx(p.Mass, box.Mass) || !approx(p.Inertia, box.Inertia) {
+		t.Errorf("square polygon should weigh like a box: mass %.3f/%.3f, inertia %.3f/%.3f", p.Mass, box.Mass, p.Inertia, box.Inertia)
+	}
+
+	if p := NewPolygon([]Point{{X: 0, Y: 0}, {X: 1, Y: 1}, {X: 2, Y: 2}}, color.RGBA{}, Vector{}); p != nil {
+		t.Errorf("collinear points should not make a polygon, got %+v", p.Local)
+	}
+}
+
+func TestPolygonCollisions(t *testing.T) {
+	hex := NewRegularPolygon(0, 0, 10, 6, color.RGBA{}, Vector{})
+	tri := NewRegularPolygon(18, 0, 10, 3, color.RGBA{}, Vector{})
+	tri.Angle = 3.14159 // point the triangle's corner at the hexagon
+
+	flat := NewRegularPolygon(0, 0, 10, 6, color.RGBA{}, Vector{})
+	flat.Angle = 3.14159 / 6 // turn a side, rather than a corner, towards the triangle
+	col := CheckCollision(flat, tri).Deepest()
+	if !col.Hit || col.Normal.X < 0.9 {
+		t.Errorf("triangle corner in the hexagon's side: got %+v, want a hit along +x", col)
+	}
+
+	c := NewCircle(-14, 0, 5, color.RGBA{}, Vector{})
+	col = CheckCollision(hex, c).Deepest()
+	if !col.Hit || !approx(col.Depth, 1) || col.Normal.X > -0.99 {
+		t.Errorf("circle against the left corner: got %+v, want depth 1 along -x", col)
+	}
+
+	floor := NewBoundaryLine(Point{X: -50, Y: 8}, Point{X: 50, Y: 8}, 1, color.RGBA{})
+	col = CheckCollision(floor, hex).Deepest()
+	if !col.Hit || col.Normal.Y > -0.99 {
+		t.Errorf("hexagon through the floor: got %+v, want a push up", col)
+	}
+}
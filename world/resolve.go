@@ -0,0 +1,13 @@
+package world
+
+import "math"
+
+// mixFriction and mixRestitution combine the coefficients of two touching bodies: friction is the
+// geometric mean so a frictionless surface stays slippery, and the bouncier of the two wins.
+func mixFriction(a, b float32) float32 {
+	return float32(math.Sqrt(float64(a * b)))
+}
+
+func mixRestitution(a, b float32) float32 {
+	return max(a, b)
+}
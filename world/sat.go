@@ -0,0 +1,185 @@
+package world
+
+import "math"
+
+// centroid returns the average of the vertices, which is inside any convex polygon.
+func centroid(verts []Vector) Vector {
+	var c Vector
+	for _, v := range verts {
+		c = c.Add(v)
+	}
+	return c.Scale(1 / float32(len(verts)))
+}
+
+// support returns the vertex furthest along dir.
+func support(verts []Vector, dir Vector) Vector {
+	best := verts[0]
+	bestDot := best.Dot(dir)
+	for _, v := range verts[1:] {
+		if d := v.Dot(dir); d > bestDot {
+			best, bestDot = v, d
+		}
+	}
+	return best
+}
+
+// satAxis runs the separating axis test between two convex vertex sets over their face normals.
+// The returned normal is the axis of least overlap, pointing from a to b, and the bool reports
+// whether it belongs to a face of a. The contact point is the vertex of the other set that pokes
+// deepest through that face.
+func satAxis(a, b []Vector, axesA, axesB []Vector) (Collision, bool) {
+	minDepth := float32(math.MaxFloat32)
+	var normal Vector
+	faceOfA := true
+	for i, axis := range append(axesA[:len(axesA):len(axesA)], axesB...) {
+		if axis == (Vector{}) {
+			continue
+		}
+		minA, maxA := projectCorners(a, axis)
+		minB, maxB := projectCorners(b, axis)
+		if maxA < minB || maxB < minA {
+			return Collision{}, false // separating axis found
+		}
+		overlap := min(maxA-minB, maxB-minA)
+		if overlap < minDepth {
+			minDepth = overlap
+			normal = axis
+			faceOfA = i < len(axesA)
+		}
+	}
+	if normal == (Vector{}) {
+		return Collision{}, false
+	}
+
+	if normal.Dot(centroid(b).Sub(centroid(a))) < 0 {
+		normal = normal.Scale(-1)
+	}
+
+	point := support(a, normal)
+	if faceOfA {
+		point = support(b, normal.Scale(-1))
+	}
+
+	return Collision{
+		Hit:    true,
+		Normal: normal,
+		Depth:  minDepth,
+		Point:  point,
+	}, faceOfA
+}
+
+// satManifold runs satAxis and then clips the incident edge, the one facing back into the face of
+// least overlap, against the sides of that reference face. Every clipped point still behind the
+// reference face becomes a contact, giving two for edges lying against each other and one for a
+// corner. A plain segment works as a two vertex polygon.
+func satManifold(a, b []Vector, axesA, axesB []Vector) Manifold {
+	col, faceOfA := satAxis(a, b, axesA, axesB)
+	if !col.Hit {
+		return Manifold{}
+	}
+
+	// the reference face faces along out; the incident edge faces back against it
+	ref, inc, out := a, b, col.Normal
+	if !faceOfA {
+		ref, inc, out = b, a, col.Normal.Scale(-1)
+	}
+	r1, r2 := bestEdge(ref, out)
+	i1, i2 := bestEdge(inc, out.Scale(-1))
+
+	side := r2.Sub(r1).Normalize()
+	points := clipSegment(i1, i2, side, side.Dot(r1))
+	if len(points) == 2 {
+		points = clipSegment(points[0], points[1], side.Scale(-1), -side.Dot(r2))
+	}
+	if len(points) < 2 {
+		return single(col)
+	}
+
+	face := max(out.Dot(r1), out.Dot(r2))
+	var m Manifold
+	for _, p := range points {
+		if depth := face - out.Dot(p); depth >= 0 {
+			m.Add(Collision{Hit: true, Normal: col.Normal, Depth: depth, Point: p})
+		}
+	}
+	if !m.Hit() {
+		return single(col)
+	}
+	return m
+}
+
+// bestEdge returns the edge through the vertex furthest along dir that is closest to
+// perpendicular to dir.
+func bestEdge(verts []Vector, dir Vector) (Vector, Vector) {
+	i := 0
+	for j, v := range verts {
+		if v.Dot(dir) > verts[i].Dot(dir) {
+			i = j
+		}
+	}
+	v := verts[i]
+	prev := verts[(i+len(verts)-1)%len(verts)]
+	next := verts[(i+1)%len(verts)]
+	if math.Abs(float64(next.Sub(v).Normalize().Dot(dir))) <= math.Abs(float64(v.Sub(prev).Normalize().Dot(dir))) {
+		return v, next
+	}
+	return prev, v
+}
+
+// clipSegment keeps the part of the segment a-b where dir·p >= offset.
+func clipSegment(a, b, dir Vector, offset float32) []Vector {
+	da, db := dir.Dot(a)-offset, dir.Dot(b)-offset
+	var out []Vector
+	if da >= 0 {
+		out = append(out, a)
+	}
+	if db >= 0 {
+		out = append(out, b)
+	}
+	if da*db < 0 {
+		out = append(out, a.Add(b.Sub(a).Scale(da/(da-db))))
+	}
+	return out
+}
+
+// circleVsPolygon tests a circle against a convex polygon. The normal points from the circle to
+// the polygon, and the contact point is the closest point on the polygon's outline.
+func circleVsPolygon(center Vector, radius float32, verts []Vector) Collision {
+	var closest Vector
+	closestDist := float32(math.MaxFloat32)
+	inside := true
+	c := centroid(verts)
+	for i := range verts {
+		edge := Line{From: Point(verts[i]), To: Point(verts[(i+1)%len(verts)])}
+		p := edge.ClosestPoint(center)
+		if d := center.Sub(p).Length(); d < closestDist {
+			closest, closestDist = p, d
+		}
+		// the center is outside if it's beyond any edge, measured with the normal facing out
+		n := edge.Normal()
+		if n.Dot(verts[i].Sub(c)) < 0 {
+			n = n.Scale(-1)
+		}
+		if n.Dot(center.Sub(verts[i])) > 0 {
+			inside = false
+		}
+	}
+
+	if inside {
+		// push the circle out through the nearest edge
+		normal := center.Sub(closest).Normalize()
+		if closestDist == 0 {
+			normal = c.Sub(center).Normalize()
+		}
+		return Collision{Hit: true, Normal: normal, Depth: radius + closestDist, Point: closest}
+	}
+	if closestDist >= radius {
+		return Collision{}
+	}
+	return Collision{
+		Hit:    true,
+		Normal: closest.Sub(center).Normalize(),
+		Depth:  radius - closestDist,
+		Point:  closest,
+	}
+}
@@ -0,0 +1,73 @@
+package world
+
+import (
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestBoxVsBox(t *testing.T) {
+	a := NewBox(0, 0, 10, 10, color.RGBA{}, Vector{})
+	b := NewBox(8, 1, 10, 10, color.RGBA{}, Vector{})
+	col := BoxVsBox(a, b).Deepest()
+	if !col.Hit || !approx(col.Depth, 2) || col.Normal != (Vector{X: 1, Y: 0}) {
+		t.Errorf("overlapping boxes: got %+v, want depth 2 along +x", col)
+	}
+
+	// a diamond whose bounding box overlaps the corner of a, but whose faces don't
+	c := NewBox(11, 11, 10, 10, color.RGBA{}, Vector{})
+	c.Angle = math.Pi / 4
+	if col := BoxVsBox(a, c); col.Hit() {
+		t.Errorf("diamond clear of the corner should not hit: %+v", col)
+	}
+	c.X, c.Y = 14, 5
+	if col := BoxVsBox(a, c).Deepest(); !col.Hit || col.Normal.X <= 0 {
+		t.Errorf("diamond poking into the right face should hit along +x: %+v", col)
+	}
+}
+
+func TestCircleVsBox(t *testing.T) {
+	b := NewBox(0, 0, 20, 10, color.RGBA{}, Vector{})
+
+	c := NewCircle(10, -4, 5, color.RGBA{}, Vector{})
+	col := CircleVsBox(c, b)
+	if !col.Hit || !approx(col.Depth, 1) || col.Normal != (Vector{X: 0, Y: 1}) {
+		t.Errorf("circle on the top face: got %+v, want depth 1 along +y", col)
+	}
+
+	c = NewCircle(10, 3, 5, color.RGBA{}, Vector{})
+	col = CircleVsBox(c, b)
+	if !col.Hit || !approx(col.Depth, 8) || col.Normal != (Vector{X: 0, Y: 1}) {
+		t.Errorf("center inside the box: got %+v, want depth 8 out through the top", col)
+	}
+
+	c = NewCircle(24, 14, 5, color.RGBA{}, Vector{})
+	if col := CircleVsBox(c, b); col.Hit {
+		t.Errorf("circle just past the corner should miss: %+v", col)
+	}
+}
+
+func TestBoxRestsOnBoundary(t *testing.T) {
+	w := New()
+	w.Gravity = true
+	floor := NewBoundaryLine(Point{X: -1000, Y: 100}, Point{X: 1000, Y: 100}, 1, color.RGBA{})
+	box := NewBox(80, 40, 20, 20, color.RGBA{}, Vector{})
+	box.Angle = 0.3
+	box.Restitution = 0
+	box.Friction = 0.8
+	w.Add(floor, box)
+
+	for range 600 {
+		if err := w.Step(w.TimeStep); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, corner := range box.Corners() {
+		if corner.Y > 101 {
+			t.Fatalf("box sank into the floor: corners %v", box.Corners())
+		}
+	}
+	if box.Y < 80 {
+		t.Errorf("box should have fallen onto the floor, center y = %.2f", box.Y)
+	}
+}
@@ -0,0 +1,179 @@
+package world
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"slices"
+)
+
+// Sensor is a region that notices bodies overlapping it without pushing them, for goals, kill
+// zones and checkpoints. Its Shape is a *Circle, *Box or *Polygon that is only ever used for the
+// narrowphase test; it doesn't move or collide. After every step the World calls OnEnter for each
+// body that has started overlapping the sensor, OnStay for each that still does and OnExit for
+// each that has left it or been removed from the world. Bodies that are parts of a composite, like
+// the corners of a Cube, are reported on their own.
+type Sensor struct {
+	Name   string
+	Shape  Rigid
+	Filter Filter
+	Color  color.Color
+
+	OnEnter func(s *Sensor, o Object)
+	OnStay  func(s *Sensor, o Object)
+	OnExit  func(s *Sensor, o Object)
+
+	inside []Object
+}
+
+// NewCircleSensor creates a round sensor centered at x, y.
+func NewCircleSensor(x, y, radius float32, color color.Color) *Sensor {
+	return newSensor(NewCircle(x, y, radius, color, Vector{}), color)
+}
+
+// NewRectSensor creates a rectangular sensor with its top left corner at x, y.
+func NewRectSensor(x, y, w, h float32, color color.Color) *Sensor {
+	return newSensor(NewBox(x, y, w, h, color, Vector{}), color)
+}
+
+// NewPolygonSensor creates a sensor over the convex hull of points, or returns nil if they don't
+// enclose any area.
+func NewPolygonSensor(points []Point, color color.Color) *Sensor {
+	p := NewPolygon(points, color, Vector{})
+	if p == nil {
+		return nil
+	}
+	return newSensor(p, color)
+}
+
+func newSensor(shape Rigid, color color.Color) *Sensor {
+	shape.RigidBody().Mass = 0
+	return &Sensor{Shape: shape, Filter: DefaultFilter, Color: color}
+}
+
+// Inside returns the bodies overlapping the sensor as of the last step.
+func (s *Sensor) Inside() []Object {
+	return slices.Clone(s.inside)
+}
+
+func (s *Sensor) CollisionFilter() Filter {
+	return s.Filter
+}
+
+func (s *Sensor) Bounds() AABB {
+	return s.Shape.(Bounded).Bounds()
+}
+
+// Update does nothing; sensors don't move.
+func (s *Sensor) Update(delta float32) error {
+	return nil
+}
+
+// detect finds the bodies among objects that overlap the sensor now, and fires its events.
+func (s *Sensor) detect(objects []Object) {
+	bounds := s.Bounds()
+	var inside []Object
+	for _, o := range objects {
+		r, ok := o.(Rigid)
+		if !ok || !canCollide(s, o) {
+			continue
+		}
+		if r.RigidBody().Sleeping {
+			// a sleeping body hasn't moved, so it's still wherever it was
+			if slices.Contains(s.inside, o) {
+				inside = append(inside, o)
+			}
+			continue
+		}
+		if b, ok := o.(Bounded); ok && !bounds.Overlaps(b.Bounds()) {
+			continue
+		}
+		if CheckCollision(s.Shape, o).Hit() {
+			inside = append(inside, o)
+		}
+	}
+
+	for _, o := range s.inside {
+		if !slices.Contains(inside, o) && s.OnExit != nil {
+			s.OnExit(s, o)
+		}
+	}
+	for _, o := range inside {
+		switch {
+		case !slices.Contains(s.inside, o):
+			if s.OnEnter != nil {
+				s.OnEnter(s, o)
+			}
+		case s.OnStay != nil:
+			s.OnStay(s, o)
+		}
+	}
+	s.inside = inside
+}
+
+// updateSensors has every sensor in the world look for the bodies overlapping it.
+func (w *World) updateSensors() {
+	var objects []Object
+	for _, o := range w.Objects {
+		if s, ok := o.(*Sensor); ok {
+			if objects == nil {
+				objects = w.colliders()
+			}
+			s.detect(objects)
+		}
+	}
+}
+
+type sensorJSON struct {
+	Type   string          `json:"type"`
+	Name   string          `json:"name,omitempty"`
+	Shape  json.RawMessage `json:"shape"`
+	Filter Filter          `json:"filter"`
+	ColorR uint8           `json:"R"`
+	ColorG uint8           `json:"G"`
+	ColorB uint8           `json:"B"`
+	ColorA uint8           `json:"A"`
+}
+
+func (s *Sensor) MarshalJSON() ([]byte, error) {
+	shape, err := json.Marshal(s.Shape)
+	if err != nil {
+		return nil, err
+	}
+	c := s.Color.(color.RGBA)
+	return json.Marshal(sensorJSON{
+		Type:   "Sensor",
+		Name:   s.Name,
+		Shape:  shape,
+		Filter: s.Filter,
+		ColorR: c.R,
+		ColorG: c.G,
+		ColorB: c.B,
+		ColorA: c.A,
+	})
+}
+
+func (s *Sensor) UnmarshalJSON(data []byte) error {
+	aux := sensorJSON{Filter: DefaultFilter}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	shape, err := unmarshalObject(aux.Shape)
+	if err != nil {
+		return err
+	}
+	switch shape := shape.(type) {
+	case *Circle, *Box, *Polygon:
+		s.Shape = shape.(Rigid)
+		s.Shape.RigidBody().Mass = 0
+	default:
+		return fmt.Errorf("sensor %q has a %T for a shape, want a circle, box or polygon", aux.Name, shape)
+	}
+	s.Name = aux.Name
+	s.Filter = aux.Filter
+	reserveGroup(aux.Filter.Group)
+	s.Color = color.RGBA{R: aux.ColorR, G: aux.ColorG, B: aux.ColorB, A: aux.ColorA}
+	s.inside = nil
+	return nil
+}
@@ -0,0 +1,101 @@
+package world
+
+import (
+	"image/color"
+	"path/filepath"
+	"testing"
+)
+
+// sensorEvents counts the events a sensor fires.
+type sensorEvents struct {
+	enter, stay, exit int
+}
+
+func watch(s *Sensor) *sensorEvents {
+	e := &sensorEvents{}
+	s.OnEnter = func(*Sensor, Object) { e.enter++ }
+	s.OnStay = func(*Sensor, Object) { e.stay++ }
+	s.OnExit = func(*Sensor, Object) { e.exit++ }
+	return e
+}
+
+func TestSensorSeesBodyPassThrough(t *testing.T) {
+	for _, sensor := range []*Sensor{
+		NewRectSensor(-50, 100, 100, 50, color.RGBA{}),
+		NewCircleSensor(0, 125, 30, color.RGBA{}),
+		NewPolygonSensor([]Point{{X: -50, Y: 100}, {X: 50, Y: 100}, {X: 0, Y: 150}}, color.RGBA{}),
+	} {
+		w := New()
+		w.Gravity = true
+		ball := NewCircle(0, 0, 5, color.RGBA{}, Vector{})
+		w.Add(sensor, ball)
+		e := watch(sensor)
+
+		for range 60 {
+			if err := w.Step(w.TimeStep); err != nil {
+				t.Fatal(err)
+			}
+		}
+		shape := sensor.Shape.(Object)
+		if e.enter != 1 || e.exit != 1 || e.stay == 0 {
+			t.Errorf("%T sensor: got %+v, want one enter, some stays and one exit", shape, *e)
+		}
+		// one second of free fall, untouched by the sensor
+		if want := GravityConstant; ball.Velocity.Y < want*0.99 || ball.Velocity.X != 0 {
+			t.Errorf("%T sensor pushed the ball: velocity %+v", shape, ball.Velocity)
+		}
+	}
+}
+
+func TestSensorFilterAndRemoval(t *testing.T) {
+	w := New()
+	sensor := NewCircleSensor(0, 0, 50, color.RGBA{})
+	sensor.Filter.Mask = 1 << 3
+	player := NewCircle(0, 0, 5, color.RGBA{}, Vector{})
+	player.Filter.Category = 1 << 3
+	rock := NewCircle(10, 0, 5, color.RGBA{}, Vector{})
+	w.Add(sensor, player, rock)
+	e := watch(sensor)
+
+	if err := w.Step(w.TimeStep); err != nil {
+		t.Fatal(err)
+	}
+	if inside := sensor.Inside(); len(inside) != 1 || inside[0] != player {
+		t.Fatalf("only the player should be seen, got %v", inside)
+	}
+
+	w.Objects = []Object{sensor, rock}
+	if err := w.Step(w.TimeStep); err != nil {
+		t.Fatal(err)
+	}
+	if e.exit != 1 || len(sensor.Inside()) != 0 {
+		t.Errorf("removing the player should fire exit: %+v", *e)
+	}
+}
+
+func TestSensorSaveLoad(t *testing.T) {
+	w := New()
+	goal := NewRectSensor(10, 20, 30, 40, color.RGBA{G: 255, A: 255})
+	goal.Name = "goal"
+	goal.Filter.Mask = 2
+	w.Add(goal)
+	filename := filepath.Join(t.TempDir(), "save.json")
+	if err := w.SaveState(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := New()
+	if err := loaded.LoadState(filename); err != nil {
+		t.Fatal(err)
+	}
+	got, ok := loaded.Objects[0].(*Sensor)
+	if !ok {
+		t.Fatalf("got %T, want *Sensor", loaded.Objects[0])
+	}
+	if got.Name != "goal" || got.Filter != goal.Filter || got.Bounds() != goal.Bounds() {
+		t.Errorf("sensor did not round trip: %q %+v %+v", got.Name, got.Filter, got.Bounds())
+	}
+	if _, ok := got.Shape.(*Box); !ok {
+		t.Errorf("got a %T shape, want *Box", got.Shape)
+	}
+}
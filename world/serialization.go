@@ -0,0 +1,177 @@
+package world
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+func (w *World) SaveState(filename string) error {
+	b, err := json.Marshal(w)
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(b)
+	if err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return nil
+}
+
+type typeChecker struct {
+	Type string `json:"Type"`
+}
+
+func (w *World) LoadState(filename string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	w.Objects = nil // Clear existing objects
+	w.Joints = nil  // joints aren't saved, and would hold on to the old bodies
+	w.islands = nil
+	w.contacts = nil
+	decoder := json.NewDecoder(f)
+	if err := decoder.Decode(w); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// MarshalJSON saves the world with its objects. Springs that are objects of their own are saved
+// here, since their ends are saved as the indexes of circles among the other objects.
+func (w *World) MarshalJSON() ([]byte, error) {
+	type Alias World
+	objects := make([]any, len(w.Objects))
+	for i, o := range w.Objects {
+		objects[i] = o
+		if s, ok := o.(*Spring); ok {
+			saved, err := marshalSpring(s, w.Objects)
+			if err != nil {
+				return nil, err
+			}
+			objects[i] = saved
+		}
+	}
+	return json.Marshal(struct {
+		*Alias
+		Objects []any `json:"Objects"`
+	}{
+		Alias:   (*Alias)(w),
+		Objects: objects,
+	})
+}
+
+func (w *World) UnmarshalJSON(data []byte) error {
+	type Alias World
+	aux := struct {
+		*Alias
+		Objects []json.RawMessage `json:"Objects"`
+	}{
+		Alias: (*Alias)(w),
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	// springs are joined up once everything else is loaded, since their ends may come after them
+	var springs []int
+	start := len(w.Objects)
+	for i, objData := range aux.Objects {
+		var tc typeChecker
+		if err := json.Unmarshal(objData, &tc); err != nil {
+			return err
+		}
+		if tc.Type == "Spring" {
+			springs = append(springs, i)
+			w.Objects = append(w.Objects, nil)
+			continue
+		}
+		o, err := unmarshalObject(objData)
+		if err != nil {
+			return err
+		}
+		w.Objects = append(w.Objects, o)
+	}
+	for _, i := range springs {
+		s, err := unmarshalSpring(aux.Objects[i], w.Objects[start:])
+		if err != nil {
+			return err
+		}
+		w.Objects[start+i] = s
+	}
+	return nil
+}
+
+// unmarshalObject decodes one saved object by its type, failing on types it doesn't know.
+func unmarshalObject(data json.RawMessage) (Object, error) {
+	var tc typeChecker
+	if err := json.Unmarshal(data, &tc); err != nil {
+		return nil, err
+	}
+	switch tc.Type {
+	case "Circle":
+		var c Circle
+		err := json.Unmarshal(data, &c)
+		return &c, err
+	case "Boundary":
+		var b Boundary
+		err := json.Unmarshal(data, &b)
+		return &b, err
+	case "CubeBoundary":
+		var b CubeBoundary
+		err := json.Unmarshal(data, &b)
+		return &b, err
+	case "Box":
+		var b Box
+		err := json.Unmarshal(data, &b)
+		return &b, err
+	case "Polygon":
+		var p Polygon
+		err := json.Unmarshal(data, &p)
+		return &p, err
+	case "SoftBody":
+		var sb SoftBody
+		err := json.Unmarshal(data, &sb)
+		return &sb, err
+	case "Cloth":
+		var cl Cloth
+		err := json.Unmarshal(data, &cl)
+		return &cl, err
+	case "Sensor":
+		var s Sensor
+		err := json.Unmarshal(data, &s)
+		return &s, err
+	case "ForceField":
+		var f ForceField
+		err := json.Unmarshal(data, &f)
+		return &f, err
+	case "Fluid":
+		var f Fluid
+		err := json.Unmarshal(data, &f)
+		return &f, err
+	case "Liquid":
+		var l Liquid
+		err := json.Unmarshal(data, &l)
+		return &l, err
+	case "Kinematic":
+		var k Kinematic
+		err := json.Unmarshal(data, &k)
+		return &k, err
+	case "Cube":
+		var c Cube
+		err := json.Unmarshal(data, &c)
+		return &c, err
+	}
+	return nil, fmt.Errorf("unknown object type %q", tc.Type)
+}
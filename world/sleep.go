@@ -0,0 +1,256 @@
+package world
+
+const (
+	DefaultSleepTime            = 0.5
+	DefaultSleepVelocity        = 8
+	DefaultSleepAngularVelocity = 0.1
+)
+
+// island is a group of bodies that touch or are joined, directly or through each other. An island
+// only falls asleep once all of it has come to rest, and wakes up all at once.
+type island []*Body
+
+// sleep stops b dead and marks it asleep.
+func (b *Body) sleep() {
+	b.Sleeping = true
+	b.Velocity = Vector{}
+	b.AngularVelocity = 0
+	b.LastPosition = b.Point
+}
+
+func (b *Body) wake() {
+	b.Sleeping = false
+	b.sleepTime = 0
+}
+
+// Asleep reports whether o is sleeping: a body that is, or a composite or spring all of whose
+// movable bodies are.
+func Asleep(o Object) bool {
+	switch o := o.(type) {
+	case Rigid:
+		return o.RigidBody().Sleeping
+	case Composite:
+		asleep := false
+		for _, p := range o.Parts() {
+			if r, ok := p.(Rigid); ok && r.RigidBody().Mass != 0 {
+				if !r.RigidBody().Sleeping {
+					return false
+				}
+				asleep = true
+			}
+		}
+		return asleep
+	case *Spring:
+		a, b := o.Ends()
+		return (a.Sleeping || a.Mass == 0) && (b.Sleeping || b.Mass == 0) && (a.Sleeping || b.Sleeping)
+	}
+	return false
+}
+
+// resting reports whether o can't move this step, because it's static or asleep. Kinematic
+// bodies and containers are static too, but aren't resting while they move.
+func resting(o Object) bool {
+	if c, ok := o.(*CubeBoundary); ok {
+		return !c.Moving()
+	}
+	r, ok := o.(Rigid)
+	if !ok {
+		return true
+	}
+	b := r.RigidBody()
+	return b.Sleeping || (b.Mass == 0 && b.Velocity == (Vector{}) && b.AngularVelocity == 0)
+}
+
+// Wake wakes o, and everything asleep in the same island as it. Wake anything that was resting on
+// a body that's moved or been removed, or it'll hang in the air until something bumps it.
+func (w *World) Wake(o Object) {
+	switch o := o.(type) {
+	case Rigid:
+		w.wake(o.RigidBody())
+	case Composite:
+		for _, p := range o.Parts() {
+			w.Wake(p)
+		}
+	case *Spring:
+		a, b := o.Ends()
+		w.wake(&a.Body)
+		w.wake(&b.Body)
+	}
+}
+
+// WakeAll wakes every body in the world.
+func (w *World) WakeAll() {
+	for _, o := range w.colliders() {
+		if r, ok := o.(Rigid); ok {
+			r.RigidBody().wake()
+		}
+	}
+	w.islands = nil
+}
+
+// wakeInMovingContainers wakes everything asleep within a container whose walls are moving. A floor
+// dropping away never touches what was resting on it, so nothing else would wake it to fall.
+func (w *World) wakeInMovingContainers() {
+	for _, o := range w.Objects {
+		c, ok := o.(*CubeBoundary)
+		if !ok || !c.Moving() {
+			continue
+		}
+		bounds := c.Bounds()
+		for _, o := range w.colliders() {
+			r, ok := o.(Rigid)
+			if !ok || !r.RigidBody().Sleeping {
+				continue
+			}
+			if b, ok := o.(Bounded); ok && b.Bounds().Overlaps(bounds) {
+				w.wake(r.RigidBody())
+			}
+		}
+	}
+}
+
+func (w *World) wake(b *Body) {
+	if !b.Sleeping {
+		return
+	}
+	island, ok := w.islands[b]
+	if !ok {
+		b.wake()
+		return
+	}
+	for _, ib := range island {
+		ib.wake()
+		delete(w.islands, ib)
+	}
+}
+
+// updateSleep times how long every awake body has been at rest and puts to sleep the islands that
+// have all been resting for SleepTime.
+func (w *World) updateSleep(delta float32) {
+	if !w.AllowSleep {
+		return
+	}
+
+	// build the islands with a union find over the awake bodies
+	parent := make(map[*Body]*Body)
+	var bodies []*Body
+	add := func(b *Body) {
+		if b.Sleeping {
+			return
+		}
+		if _, ok := parent[b]; !ok {
+			parent[b] = b
+			bodies = append(bodies, b)
+		}
+	}
+	find := func(b *Body) *Body {
+		for parent[b] != b {
+			parent[b] = parent[parent[b]]
+			b = parent[b]
+		}
+		return b
+	}
+	// union joins the islands of a and b; either can be nil or static, like the world end of a
+	// joint, which doesn't join anything
+	union := func(a, b *Body) {
+		movable := func(b *Body) bool { return b != nil && b.Mass != 0 }
+		switch {
+		case !movable(a) && !movable(b):
+		case !movable(a):
+			add(b)
+		case !movable(b):
+			add(a)
+		case a.Sleeping && b.Sleeping:
+		default:
+			// anything joined to an awake body stays awake with it
+			w.wake(a)
+			w.wake(b)
+			add(a)
+			add(b)
+			parent[find(a)] = find(b)
+		}
+	}
+
+	for _, o := range w.Objects {
+		if Asleep(o) {
+			continue
+		}
+		switch o := o.(type) {
+		case Rigid:
+			union(o.RigidBody(), nil)
+		case Composite:
+			var first *Body
+			for _, p := range o.Parts() {
+				if r, ok := p.(Rigid); ok && r.RigidBody().Mass != 0 {
+					if first == nil {
+						first = r.RigidBody()
+					}
+					union(first, r.RigidBody())
+				}
+			}
+		case *Spring:
+			a, b := o.Ends()
+			union(&a.Body, &b.Body)
+		}
+	}
+	for _, j := range w.Joints {
+		union(j.Bodies())
+	}
+	// a kinematic shape that's paused will move on again, and anything asleep on it would be left
+	// hanging in the air, so bodies touching one stay awake
+	kinematic := make(map[*Body]bool)
+	for _, o := range w.Objects {
+		if k, ok := o.(*Kinematic); ok {
+			kinematic[k.Shape.RigidBody()] = true
+		}
+	}
+	var carried []*Body
+	for _, t := range w.touching {
+		union(t[0], t[1])
+		if kinematic[t[0]] {
+			carried = append(carried, t[1])
+		} else if kinematic[t[1]] {
+			carried = append(carried, t[0])
+		}
+	}
+
+	for _, b := range bodies {
+		if b.Velocity.Length() > w.SleepVelocity || max(b.AngularVelocity, -b.AngularVelocity) > w.SleepAngularVelocity {
+			b.sleepTime = 0
+		} else {
+			b.sleepTime += delta
+		}
+	}
+	islands := make(map[*Body]island)
+	for _, b := range bodies {
+		root := find(b)
+		islands[root] = append(islands[root], b)
+	}
+	for _, b := range carried {
+		if _, ok := parent[b]; ok {
+			delete(islands, find(b))
+		}
+	}
+	for _, is := range islands {
+		if !is.rested(w.SleepTime) {
+			continue
+		}
+		if w.islands == nil {
+			w.islands = make(map[*Body]island)
+		}
+		for _, b := range is {
+			b.sleep()
+			w.islands[b] = is
+		}
+	}
+}
+
+// rested reports whether every body in the island has been at rest for at least d seconds.
+func (is island) rested(d float32) bool {
+	for _, b := range is {
+		if b.sleepTime < d {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,85 @@
+package world
+
+import (
+	"image/color"
+	"testing"
+)
+
+// stackOfTwo returns a gravity world with two boxes stacked on a floor at y = 100.
+func stackOfTwo() (*World, *Box, *Box) {
+	w := New()
+	w.Gravity = true
+	floor := NewBoundaryLine(Point{X: -200, Y: 100}, Point{X: 200, Y: 100}, 1, color.RGBA{})
+	bottom := NewBox(-10, 80, 20, 20, color.RGBA{}, Vector{})
+	top := NewBox(-10, 60, 20, 20, color.RGBA{}, Vector{})
+	bottom.Restitution, top.Restitution = 0, 0
+	w.Add(floor, bottom, top)
+	return w, bottom, top
+}
+
+func steps(t *testing.T, w *World, n int) {
+	t.Helper()
+	for range n {
+		if err := w.Step(w.TimeStep); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRestingStackFallsAsleep(t *testing.T) {
+	w, bottom, top := stackOfTwo()
+	steps(t, w, 120)
+	if !bottom.Sleeping || !top.Sleeping {
+		t.Fatalf("resting stack should be asleep: bottom %t, top %t", bottom.Sleeping, top.Sleeping)
+	}
+
+	at := top.Point
+	count := w.CollisionCount
+	steps(t, w, 60)
+	if top.Point != at {
+		t.Errorf("sleeping box moved from %+v to %+v", at, top.Point)
+	}
+	if w.CollisionCount != count {
+		t.Errorf("sleeping stack was still collision checked: %d contacts", w.CollisionCount-count)
+	}
+}
+
+func TestTouchWakesTheIsland(t *testing.T) {
+	w, bottom, top := stackOfTwo()
+	steps(t, w, 120)
+
+	ball := NewCircle(0, 0, 5, color.RGBA{}, Vector{Y: 200})
+	ball.Restitution = 0
+	w.Add(ball)
+	var woke bool
+	for range 30 {
+		steps(t, w, 1)
+		woke = woke || (!bottom.Sleeping && !top.Sleeping)
+	}
+	if !woke {
+		t.Fatal("a ball landing on the top box should wake the whole stack")
+	}
+	steps(t, w, 180)
+	if !bottom.Sleeping || !top.Sleeping || !ball.Sleeping {
+		t.Errorf("the stack and ball should settle back to sleep together: bottom %t, top %t, ball %t at %+v moving %+v",
+			bottom.Sleeping, top.Sleeping, ball.Sleeping, ball.Point, ball.Velocity)
+	}
+}
+
+func TestWakeAllAfterRemovingSupport(t *testing.T) {
+	w, _, top := stackOfTwo()
+	steps(t, w, 120)
+
+	// take the bottom box out from under the top one
+	w.Objects = []Object{w.Objects[0], top}
+	at := top.Point
+	steps(t, w, 10)
+	if top.Point != at {
+		t.Fatalf("top box should hang asleep in the air until woken, moved to %+v", top.Point)
+	}
+	w.WakeAll()
+	steps(t, w, 60)
+	if top.Y < 85 {
+		t.Errorf("woken box should have fallen to the floor, y = %.2f", top.Y)
+	}
+}
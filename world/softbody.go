@@ -0,0 +1,182 @@
+package world
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"math"
+)
+
+const (
+	// softEdgeStiffness and softEdgeDamping are per unit of node mass, like the Cube's springs.
+	// The edges have to be much stiffer than the Cube's to hold the gas in, which is only stable
+	// because stepNodes steps them softSubsteps times per step.
+	softEdgeStiffness = 8000
+	softEdgeDamping   = 60
+	softSubsteps      = 4
+	// softBraceStiffness is weaker than the edges, so braced bodies still squash.
+	softBraceStiffness = softEdgeStiffness / 8
+	// DefaultSoftPressure is the gas pressure, in force per pixel of outline, of a soft body at its
+	// rest area.
+	DefaultSoftPressure = 150
+)
+
+// SoftBody is a ring of Circle nodes joined by Springs along its outline and filled with gas. The
+// gas pushes every edge outward, harder the more the enclosed area is squeezed below RestArea, so
+// a SoftBody squashes against walls and springs back like a jelly or a balloon. The nodes collide
+// on their own, as parts of the body.
+//
+// The whole weight of a resting SoftBody bears down on its bottom few nodes, which sink into the
+// ground between contact solves unless the World takes a few Substeps.
+type SoftBody struct {
+	Nodes []*Circle
+	// Springs are the edges of the ring, in order, followed by any cross braces.
+	Springs []*Spring
+	// Pressure is the gas pressure at RestArea; as the area changes the pressure scales inversely,
+	// like an ideal gas. Zero lets the body collapse.
+	Pressure float32
+	RestArea float32
+	Color    color.Color
+	Filled   bool
+}
+
+// NewSoftBody creates a round soft body of the given number of nodes, centered at x, y, with at
+// least three so it encloses some gas. Braced bodies also get a spring across to the opposite node,
+// which helps them keep their shape.
+func NewSoftBody(x, y, radius float32, nodes int, braced bool, color color.Color, velocity Vector) *SoftBody {
+	nodes = max(nodes, 3)
+	// a node's radius is a quarter of the spacing, so neighbours don't collide with each other
+	nodeRadius := math.Pi * radius / float32(nodes) / 2
+	s := &SoftBody{Pressure: DefaultSoftPressure, Color: color}
+	for i := range nodes {
+		sin, cos := math.Sincos(2 * math.Pi * float64(i) / float64(nodes))
+		s.Nodes = append(s.Nodes, NewCircle(x+radius*float32(cos), y+radius*float32(sin), nodeRadius, color, velocity))
+	}
+	mass := s.Nodes[0].Mass
+	for i, n := range s.Nodes {
+		edge := NewSpring(n, s.Nodes[(i+1)%nodes], softEdgeStiffness*mass, 1, color)
+		edge.Damping = softEdgeDamping * mass
+		s.Springs = append(s.Springs, edge)
+	}
+	if braced {
+		for i, n := range s.Nodes[:nodes/2] {
+			brace := NewSpring(n, s.Nodes[i+nodes/2], softBraceStiffness*mass, 1, color)
+			brace.Damping = softEdgeDamping * mass
+			s.Springs = append(s.Springs, brace)
+		}
+	}
+	s.RestArea = s.Area()
+	return s
+}
+
+// Outline returns the node centers in ring order.
+func (s *SoftBody) Outline() []Vector {
+	outline := make([]Vector, len(s.Nodes))
+	for i, n := range s.Nodes {
+		outline[i] = Vector(n.Point)
+	}
+	return outline
+}
+
+// Area is the area enclosed by the ring of nodes.
+func (s *SoftBody) Area() float32 {
+	area, _ := polygonAreaCentroid(s.Outline())
+	return area
+}
+
+// Parts returns the nodes, which collide on their own.
+func (s *SoftBody) Parts() []Object {
+	parts := make([]Object, len(s.Nodes))
+	for i, n := range s.Nodes {
+		parts[i] = n
+	}
+	return parts
+}
+
+func (s *SoftBody) Bounds() AABB {
+	b := s.Nodes[0].Bounds()
+	for _, n := range s.Nodes[1:] {
+		b = b.Union(n.Bounds())
+	}
+	return b
+}
+
+func (s *SoftBody) Update(delta float32) error {
+	return stepNodes(s.Nodes, s.Springs, delta, softSubsteps, s.applyPressure)
+}
+
+// applyPressure pushes every edge outward with the gas pressure times its length, shared between
+// its two nodes.
+func (s *SoftBody) applyPressure(delta float32) {
+	area := s.Area()
+	if s.Pressure == 0 || area == 0 {
+		return
+	}
+	pressure := s.Pressure * s.RestArea / area
+
+	// the outward side of an edge depends on which way round the ring winds
+	outline := s.Outline()
+	var winding float32
+	for i, a := range outline {
+		winding += a.Cross(outline[(i+1)%len(outline)])
+	}
+	for i, a := range s.Nodes {
+		b := s.Nodes[(i+1)%len(s.Nodes)]
+		edge := Vector(b.Point.Sub(a.Point))
+		// edge.Perp() has the edge's length, so this is pressure times length
+		outward := edge.Perp().Scale(-1)
+		if winding < 0 {
+			outward = outward.Scale(-1)
+		}
+		impulse := outward.Scale(pressure * delta / 2)
+		a.Velocity = a.Velocity.Add(impulse.Scale(a.InvMass()))
+		b.Velocity = b.Velocity.Add(impulse.Scale(b.InvMass()))
+	}
+}
+
+type softBodyJSON struct {
+	Type     string           `json:"type"`
+	Nodes    []*Circle        `json:"nodes"`
+	Springs  []nodeSpringJSON `json:"springs"`
+	Pressure float32          `json:"pressure"`
+	RestArea float32          `json:"restArea"`
+	ColorR   uint8            `json:"R"`
+	ColorG   uint8            `json:"G"`
+	ColorB   uint8            `json:"B"`
+	ColorA   uint8            `json:"A"`
+	Filled   bool             `json:"filled"`
+}
+
+func (s *SoftBody) MarshalJSON() ([]byte, error) {
+	c := s.Color.(color.RGBA)
+	return json.Marshal(softBodyJSON{
+		Type:     "SoftBody",
+		Nodes:    s.Nodes,
+		Springs:  marshalNodeSprings(s.Nodes, s.Springs),
+		Pressure: s.Pressure,
+		RestArea: s.RestArea,
+		ColorR:   c.R,
+		ColorG:   c.G,
+		ColorB:   c.B,
+		ColorA:   c.A,
+		Filled:   s.Filled,
+	})
+}
+
+func (s *SoftBody) UnmarshalJSON(data []byte) error {
+	var aux softBodyJSON
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	if len(aux.Nodes) < 3 {
+		return fmt.Errorf("soft body has %d nodes, want at least 3", len(aux.Nodes))
+	}
+
+	s.Nodes = aux.Nodes
+	s.Color = color.RGBA{R: aux.ColorR, G: aux.ColorG, B: aux.ColorB, A: aux.ColorA}
+	s.Springs = unmarshalNodeSprings(s.Nodes, aux.Springs, s.Color)
+	s.Pressure = aux.Pressure
+	s.RestArea = aux.RestArea
+	s.Filled = aux.Filled
+	return nil
+}
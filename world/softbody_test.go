@@ -0,0 +1,92 @@
+package world
+
+import (
+	"image/color"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// dropBlob lets a soft body fall onto a floor and settle, and returns it.
+func dropBlob(t *testing.T, pressure float32) *SoftBody {
+	t.Helper()
+	w := New()
+	w.Gravity = true
+	w.Substeps = 4
+	floor := NewBoundaryLine(Point{X: -1000, Y: 200}, Point{X: 1000, Y: 200}, 1, color.RGBA{})
+	blob := NewSoftBody(0, 100, 40, 16, false, color.RGBA{}, Vector{})
+	blob.Pressure = pressure
+	w.Add(floor, blob)
+	for range 300 {
+		if err := w.Step(w.TimeStep); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return blob
+}
+
+func TestSoftBodySquashes(t *testing.T) {
+	blob := dropBlob(t, DefaultSoftPressure)
+	b := blob.Bounds()
+	if math.IsNaN(float64(b.Max.Y)) {
+		t.Fatalf("blob blew up: %+v", b)
+	}
+	if b.Max.Y > 201 {
+		t.Fatalf("blob sank into the floor: bottom at %.2f", b.Max.Y)
+	}
+	if b.Max.Y < 195 {
+		t.Fatalf("blob should have landed on the floor: bottom at %.2f", b.Max.Y)
+	}
+	if w, h := b.Max.X-b.Min.X, b.Max.Y-b.Min.Y; h >= w {
+		t.Errorf("resting blob should be squashed wider than it is tall: %.1f x %.1f", w, h)
+	}
+	if area := blob.Area(); area < blob.RestArea*0.7 {
+		t.Errorf("gas should hold the blob up: area %.0f of %.0f", area, blob.RestArea)
+	}
+
+	flat := dropBlob(t, 0)
+	if flat.Area() >= blob.Area() {
+		t.Errorf("without pressure the blob should sag further: area %.0f vs %.0f", flat.Area(), blob.Area())
+	}
+}
+
+func TestSoftBodySaveLoad(t *testing.T) {
+	w := New()
+	blob := NewSoftBody(50, 50, 20, 8, true, color.RGBA{B: 255, A: 255}, Vector{})
+	w.Add(blob)
+	filename := filepath.Join(t.TempDir(), "save.json")
+	if err := w.SaveState(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := New()
+	if err := loaded.LoadState(filename); err != nil {
+		t.Fatal(err)
+	}
+	got, ok := loaded.Objects[0].(*SoftBody)
+	if !ok {
+		t.Fatalf("got %T, want *SoftBody", loaded.Objects[0])
+	}
+	if len(got.Nodes) != 8 || len(got.Springs) != len(blob.Springs) || got.RestArea != blob.RestArea {
+		t.Fatalf("soft body did not round trip: %d nodes, %d springs, rest area %.1f", len(got.Nodes), len(got.Springs), got.RestArea)
+	}
+	from, to := got.Springs[len(got.Springs)-1].Ends()
+	if from != got.Nodes[3] || to != got.Nodes[7] {
+		t.Errorf("last brace should join nodes 3 and 7 of the loaded body")
+	}
+}
+
+func TestSoftBodyNeedsThreeNodes(t *testing.T) {
+	if blob := NewSoftBody(0, 0, 20, 0, true, color.RGBA{}, Vector{}); len(blob.Nodes) != 3 || blob.RestArea <= 0 {
+		t.Errorf("got %d nodes enclosing %v, want a triangle", len(blob.Nodes), blob.RestArea)
+	}
+
+	filename := filepath.Join(t.TempDir(), "save.json")
+	if err := os.WriteFile(filename, []byte(`{"Objects":[{"type":"SoftBody","nodes":[]}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := New().LoadState(filename); err == nil {
+		t.Error("loading a soft body without nodes should fail")
+	}
+}
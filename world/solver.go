@@ -0,0 +1,214 @@
+package world
+
+const (
+	// DefaultVelocityIterations and DefaultPositionIterations are the solver passes used by New.
+	DefaultVelocityIterations = 8
+	DefaultPositionIterations = 3
+	// DefaultBaumgarte and DefaultSlop are the position correction settings used by New.
+	DefaultBaumgarte = 0.2
+	DefaultSlop      = 0.5
+	// restitutionThreshold is the closing speed, in pixels per second, below which contacts
+	// don't bounce. It's about two steps of gravity, so resting bodies settle instead of hopping.
+	restitutionThreshold = 40
+	// warmStartDistance is how far, in pixels, a contact may drift between steps and still be
+	// treated as the same contact when warm starting.
+	warmStartDistance = 4
+)
+
+// solverPoint is one contact of a manifold, prepared for the solver.
+type solverPoint struct {
+	point       Vector
+	normal      Vector
+	tangent     Vector
+	ra, rb      Vector
+	depth       float32
+	normalMass  float32
+	tangentMass float32
+	// bounce is the separating speed restitution asks for along the normal.
+	bounce float32
+	// surface is how fast immovable geometry is moving at the contact, for walls moved by hand.
+	surface Vector
+	// normalImpulse and tangentImpulse accumulate over the iterations of a step.
+	normalImpulse  float32
+	tangentImpulse float32
+}
+
+// solverContact holds every contact between two objects. A nil a is immovable geometry.
+type solverContact struct {
+	a, b        *Body
+	startA      Point
+	startB      Point
+	friction    float32
+	restitution float32
+	points      []solverPoint
+}
+
+// cachedImpulse is what warm starting remembers about a contact from the previous step.
+type cachedImpulse struct {
+	point          Vector
+	normalImpulse  float32
+	tangentImpulse float32
+}
+
+// newSolverContact prepares a manifold for solving. Its normals must point from a to b.
+func newSolverContact(a, b *Body, m Manifold, restitution, friction float32) *solverContact {
+	sc := &solverContact{
+		a:           a,
+		b:           b,
+		startB:      b.Point,
+		friction:    friction,
+		restitution: restitution,
+	}
+	if a != nil {
+		sc.startA = a.Point
+	}
+
+	for _, col := range m.Contacts {
+		p := solverPoint{
+			point:   col.Point,
+			normal:  col.Normal,
+			tangent: col.Normal.Perp(),
+			rb:      Vector{X: col.Point.X - b.X, Y: col.Point.Y - b.Y},
+			depth:   col.Depth,
+		}
+		if a != nil {
+			p.ra = Vector{X: col.Point.X - a.X, Y: col.Point.Y - a.Y}
+		}
+		p.normalMass = inverse(sc.effectiveMass(p, p.normal))
+		p.tangentMass = inverse(sc.effectiveMass(p, p.tangent))
+		p.bounce = sc.bounce(p)
+		sc.points = append(sc.points, p)
+	}
+	return sc
+}
+
+// moveWith has the immovable side of the contact move at the velocity surface gives for each
+// contact point, so walls being moved by hand push and carry what they touch.
+func (sc *solverContact) moveWith(surface func(Vector) Vector) {
+	for i := range sc.points {
+		p := &sc.points[i]
+		p.surface = surface(p.point)
+		p.bounce = sc.bounce(*p)
+	}
+}
+
+// bounce is the separating speed restitution asks for at p, if it's closing fast enough to bounce.
+func (sc *solverContact) bounce(p solverPoint) float32 {
+	if vn := sc.relativeVelocity(p).Dot(p.normal); vn < -restitutionThreshold {
+		return -sc.restitution * vn
+	}
+	return 0
+}
+
+// effectiveMass is the inverse mass along dir at the contact, including how easily each body
+// turns about it.
+func (sc *solverContact) effectiveMass(p solverPoint, dir Vector) float32 {
+	rbCross := p.rb.Cross(dir)
+	k := sc.b.InvMass() + rbCross*rbCross*sc.b.InvInertia()
+	if sc.a != nil {
+		raCross := p.ra.Cross(dir)
+		k += sc.a.InvMass() + raCross*raCross*sc.a.InvInertia()
+	}
+	return k
+}
+
+// relativeVelocity is the velocity of b's side of the contact relative to a's.
+func (sc *solverContact) relativeVelocity(p solverPoint) Vector {
+	v := sc.b.Velocity.Add(p.rb.Perp().Scale(sc.b.AngularVelocity))
+	if sc.a != nil {
+		v = v.Sub(sc.a.Velocity.Add(p.ra.Perp().Scale(sc.a.AngularVelocity)))
+	} else {
+		v = v.Sub(p.surface)
+	}
+	return v
+}
+
+// applyImpulse pushes b by impulse and a by the opposite, at the given contact.
+func (sc *solverContact) applyImpulse(p solverPoint, impulse Vector) {
+	sc.b.Velocity = sc.b.Velocity.Add(impulse.Scale(sc.b.InvMass()))
+	sc.b.AngularVelocity += p.rb.Cross(impulse) * sc.b.InvInertia()
+	if sc.a != nil {
+		sc.a.Velocity = sc.a.Velocity.Sub(impulse.Scale(sc.a.InvMass()))
+		sc.a.AngularVelocity -= p.ra.Cross(impulse) * sc.a.InvInertia()
+	}
+}
+
+// warmStart reapplies the impulses the matching contacts ended the last step with.
+func (sc *solverContact) warmStart(cached []cachedImpulse) {
+	for i := range sc.points {
+		p := &sc.points[i]
+		for _, c := range cached {
+			if c.point.Sub(p.point).Length() < warmStartDistance {
+				p.normalImpulse, p.tangentImpulse = c.normalImpulse, c.tangentImpulse
+				sc.applyImpulse(*p, p.normal.Scale(p.normalImpulse).Add(p.tangent.Scale(p.tangentImpulse)))
+				break
+			}
+		}
+	}
+}
+
+// solveVelocity runs one sequential impulse pass over the contacts: friction first, limited by the
+// normal impulse so far, then the normal impulse, which may only ever push.
+func (sc *solverContact) solveVelocity() {
+	for i := range sc.points {
+		p := &sc.points[i]
+
+		vt := sc.relativeVelocity(*p).Dot(p.tangent)
+		limit := sc.friction * p.normalImpulse
+		tangentImpulse := clamp(p.tangentImpulse-vt*p.tangentMass, -limit, limit)
+		sc.applyImpulse(*p, p.tangent.Scale(tangentImpulse-p.tangentImpulse))
+		p.tangentImpulse = tangentImpulse
+
+		vn := sc.relativeVelocity(*p).Dot(p.normal)
+		normalImpulse := max(p.normalImpulse+(p.bounce-vn)*p.normalMass, 0)
+		sc.applyImpulse(*p, p.normal.Scale(normalImpulse-p.normalImpulse))
+		p.normalImpulse = normalImpulse
+	}
+}
+
+// solvePosition moves the bodies apart by a fraction of the overlap left at each contact, beyond
+// the allowed slop. It changes positions only, so the correction never adds velocity.
+func (sc *solverContact) solvePosition(baumgarte, slop float32) {
+	invMassA := float32(0)
+	if sc.a != nil {
+		invMassA = sc.a.InvMass()
+	}
+	invMassB := sc.b.InvMass()
+	invSum := invMassA + invMassB
+	if invSum == 0 {
+		return
+	}
+	for _, p := range sc.points {
+		moved := sc.b.Point.Sub(sc.startB)
+		if sc.a != nil {
+			moved = moved.Sub(sc.a.Point.Sub(sc.startA))
+		}
+		depth := p.depth - moved.Dot(p.normal)
+		correction := baumgarte * (depth - slop) / invSum
+		if correction <= 0 {
+			continue
+		}
+		if sc.a != nil {
+			sc.a.X -= p.normal.X * correction * invMassA
+			sc.a.Y -= p.normal.Y * correction * invMassA
+		}
+		sc.b.X += p.normal.X * correction * invMassB
+		sc.b.Y += p.normal.Y * correction * invMassB
+	}
+}
+
+// impulses returns the accumulated impulses for warm starting the next step.
+func (sc *solverContact) impulses() []cachedImpulse {
+	cached := make([]cachedImpulse, len(sc.points))
+	for i, p := range sc.points {
+		cached[i] = cachedImpulse{point: p.point, normalImpulse: p.normalImpulse, tangentImpulse: p.tangentImpulse}
+	}
+	return cached
+}
+
+func inverse(k float32) float32 {
+	if k == 0 {
+		return 0
+	}
+	return 1 / k
+}
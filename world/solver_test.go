@@ -0,0 +1,176 @@
+package world
+
+import (
+	"image/color"
+	"testing"
+)
+
+func approx(a, b float32) bool {
+	d := a - b
+	return d > -0.001 && d < 0.001
+}
+
+func TestCircleVsCircle(t *testing.T) {
+	tests := []struct {
+		name         string
+		massA, massB float32
+		restitution  float32
+		wantA, wantB float32
+	}{
+		{name: "equal mass elastic swaps velocities", massA: 1, massB: 1, restitution: 1, wantA: 0, wantB: 100},
+		{name: "equal mass inelastic moves together", massA: 1, massB: 1, restitution: 0, wantA: 50, wantB: 50},
+		{name: "heavy ball keeps going", massA: 3, massB: 1, restitution: 1, wantA: 50, wantB: 150},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := New()
+			a := NewCircle(0, 0, 10, color.RGBA{}, Vector{X: 100})
+			b := NewCircle(19, 0, 10, color.RGBA{}, Vector{})
+			a.Mass, b.Mass = tt.massA, tt.massB
+			a.Restitution, b.Restitution = tt.restitution, tt.restitution
+			w.Add(a, b)
+
+			steps(t, w, 1)
+			if !approx(a.Velocity.X, tt.wantA) || !approx(b.Velocity.X, tt.wantB) {
+				t.Errorf("got velocities %.3f, %.3f, want %.3f, %.3f", a.Velocity.X, b.Velocity.X, tt.wantA, tt.wantB)
+			}
+		})
+	}
+}
+
+func TestCircleBouncesOffFloor(t *testing.T) {
+	w := New()
+	floor := NewBoundaryLine(Point{X: -100, Y: 10}, Point{X: 100, Y: 10}, 1, color.RGBA{})
+	c := NewCircle(0, -10, 10, color.RGBA{}, Vector{X: 10, Y: 200})
+	c.Restitution = 0.5
+	c.Friction = 0
+	w.Add(floor, c)
+
+	steps(t, w, 30)
+	if !approx(c.Velocity.Y, -100) || !approx(c.Velocity.X, 10) {
+		t.Errorf("frictionless half bounce: got %+v, want {10 -100}", c.Velocity)
+	}
+	if c.Y > 0 {
+		t.Errorf("circle should be back above the floor, at %+v", c.Point)
+	}
+}
+
+func TestFrictionTurnsSlideIntoRoll(t *testing.T) {
+	w := New()
+	w.Gravity = true
+	floor := NewBoundaryLine(Point{X: -1000, Y: 10}, Point{X: 1000, Y: 10}, 1, color.RGBA{})
+	c := NewCircle(0, 0, 10, color.RGBA{}, Vector{X: 100})
+	c.Restitution = 0
+	c.Friction = 1
+	w.Add(floor, c)
+
+	steps(t, w, 120)
+	// the contact point ends up at rest, so it turns about a point less than a radius below its
+	// center, since it rests within the slop of the floor
+	if r := c.Velocity.X / c.AngularVelocity; c.Velocity.X >= 100 || r < c.Radius-w.Slop || r > c.Radius {
+		t.Errorf("expected rolling, got velocity %+v and spin %.3f", c.Velocity, c.AngularVelocity)
+	}
+}
+
+func TestOffCenterHitSpins(t *testing.T) {
+	w := New()
+	a := NewCircle(0, 0, 10, color.RGBA{}, Vector{X: 100})
+	b := NewCircle(18, 8, 10, color.RGBA{}, Vector{})
+	a.Friction, b.Friction = 1, 1
+	w.Add(a, b)
+
+	steps(t, w, 1)
+	if a.AngularVelocity == 0 || b.AngularVelocity == 0 {
+		t.Errorf("glancing hit with friction should spin both circles, got %.3f and %.3f", a.AngularVelocity, b.AngularVelocity)
+	}
+	if (a.AngularVelocity > 0) != (b.AngularVelocity > 0) {
+		t.Errorf("equal and opposite friction impulses on opposite sides should turn both circles the same way, got %.3f and %.3f", a.AngularVelocity, b.AngularVelocity)
+	}
+}
+
+func TestBoxStackSettles(t *testing.T) {
+	w := New()
+	w.Gravity = true
+	floor := NewBoundaryLine(Point{X: -1000, Y: 100}, Point{X: 1000, Y: 100}, 1, color.RGBA{})
+	w.Add(floor)
+	var stack []*Box
+	for i := range 5 {
+		b := NewBox(0, 80-float32(i)*20, 20, 20, color.RGBA{}, Vector{})
+		b.Restitution = 0
+		b.Friction = 0.6
+		stack = append(stack, b)
+		w.Add(b)
+	}
+
+	for range 300 {
+		if err := w.Step(w.TimeStep); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i, b := range stack {
+		want := 90 - float32(i)*20
+		if d := b.Y - want; d < -1 || d > 1.5 {
+			t.Errorf("box %d: center y = %.2f, want about %.0f", i, b.Y, want)
+		}
+		if d := b.X - 10; d < -1 || d > 1 {
+			t.Errorf("box %d drifted sideways to x = %.2f", i, b.X)
+		}
+		if v := b.Velocity.Length(); v > 5 {
+			t.Errorf("box %d still moving at %.2f px/s", i, v)
+		}
+	}
+}
+
+func TestCirclePileDoesNotSink(t *testing.T) {
+	w := New()
+	w.Gravity = true
+	var balls []*Circle
+	for i := range 10 {
+		c := NewCircle(15+float32(i%2)*30, 380-float32(i/2)*30, 14, color.RGBA{}, Vector{})
+		c.Restitution = 0
+		balls = append(balls, c)
+		w.Add(c)
+	}
+	w.Add(NewCubeBoundary(0, 0, 60, 400, 1, color.RGBA{}))
+
+	for range 600 {
+		if err := w.Step(w.TimeStep); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i, a := range balls {
+		for _, b := range balls[i+1:] {
+			if d := a.Point.Sub(b.Point); Vector(d).Length() < a.Radius+b.Radius-2 {
+				t.Errorf("balls sank into each other: %+v and %+v are %.2f apart", a.Point, b.Point, Vector(d).Length())
+			}
+		}
+		if a.Y > 400-a.Radius+1 {
+			t.Errorf("ball sank through the floor: y = %.2f", a.Y)
+		}
+	}
+}
+
+func TestWarmStartingReusesImpulses(t *testing.T) {
+	w := New()
+	w.Gravity = true
+	floor := NewBoundaryLine(Point{X: -100, Y: 100}, Point{X: 100, Y: 100}, 1, color.RGBA{})
+	box := NewBox(0, 80, 20, 20, color.RGBA{}, Vector{})
+	box.Restitution = 0
+	w.Add(floor, box)
+	w.AllowSleep = false // a sleeping box has no contacts to cache
+	for range 60 {
+		if err := w.Step(w.TimeStep); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cached := w.impulses[[2]Object{floor, box}]
+	if len(cached) != 2 {
+		t.Fatalf("got %d cached contacts, want 2", len(cached))
+	}
+	// resting, the two contacts between them hold up the box's weight for one step
+	weight := box.Mass * GravityConstant * w.TimeStep
+	if total := cached[0].normalImpulse + cached[1].normalImpulse; total < weight*0.9 || total > weight*1.1 {
+		t.Errorf("floor impulse %.3f, want about the weight %.3f", total, weight)
+	}
+}
@@ -0,0 +1,225 @@
+package world
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"math"
+	"slices"
+)
+
+// Spring pulls two circles towards its rest Length. Its force is in physical units: Stiffness is
+// force per pixel of stretch and Damping force per pixel per second of the ends moving apart along
+// the spring, so heavier ends respond more slowly.
+type Spring struct {
+	c1 *Circle
+	c2 *Circle
+
+	Length    float32
+	Stiffness float32
+	Damping   float32
+	// BreakRatio breaks the spring once it's stretched past this many times its rest Length. Zero
+	// means it never breaks.
+	BreakRatio float32
+	// Broken is set when the spring snaps; its owner removes it at the end of the step.
+	Broken bool
+
+	Thickness float32
+	Color     color.Color
+}
+
+func NewSpring(from, to *Circle, stiffness, thickness float32, color color.Color) *Spring {
+	dx := to.X - from.X
+	dy := to.Y - from.Y
+	distance := float32(math.Sqrt(float64(dx*dx + dy*dy)))
+
+	return &Spring{
+		c1:        from,
+		c2:        to,
+		Length:    distance,
+		Stiffness: stiffness,
+		Thickness: thickness,
+		Color:     color,
+	}
+}
+
+func (s *Spring) Update(delta float32) error {
+	if s.Broken {
+		return nil
+	}
+	dx := s.c2.X - s.c1.X
+	dy := s.c2.Y - s.c1.Y
+	distance := float32(math.Sqrt(float64(dx*dx + dy*dy)))
+
+	if distance == 0 {
+		return nil
+	}
+	if s.BreakRatio > 0 && distance > s.Length*s.BreakRatio {
+		s.Broken = true
+		return nil
+	}
+
+	// Normalize the direction vector
+	n := Vector{X: dx / distance, Y: dy / distance}
+
+	// Hooke's law pulls the ends together when stretched, and damping resists them separating
+	stretch := distance - s.Length
+	separating := s.c2.Velocity.Sub(s.c1.Velocity).Dot(n)
+	force := s.Stiffness*stretch + s.Damping*separating
+
+	// Apply the impulse over the step to each circle (equal and opposite)
+	impulse := n.Scale(force * delta)
+	s.c1.Velocity = s.c1.Velocity.Add(impulse.Scale(s.c1.InvMass()))
+	s.c2.Velocity = s.c2.Velocity.Sub(impulse.Scale(s.c2.InvMass()))
+	return nil
+}
+
+// Ends returns the two circles joined by the spring.
+func (s *Spring) Ends() (*Circle, *Circle) {
+	return s.c1, s.c2
+}
+
+// stepNodes moves circles held together by stiff springs, like the nodes of a SoftBody. The springs
+// and nodes are stepped substeps times per step, which keeps springs stable at stiffnesses that
+// would blow up in a single step, and force, if not nil, adds any other forces each substep.
+func stepNodes(nodes []*Circle, springs []*Spring, delta float32, substeps int, force func(delta float32)) error {
+	h := delta / float32(substeps)
+	for i := range substeps {
+		for _, sp := range springs {
+			if err := sp.Update(h); err != nil {
+				return err
+			}
+		}
+		if force != nil {
+			force(h)
+		}
+		for _, n := range nodes {
+			// nodes would roll like wheels and let the mesh skate about, so they only slide
+			n.AngularVelocity = 0
+			last := n.Point
+			if err := n.Update(h); err != nil {
+				return err
+			}
+			if i > 0 {
+				n.LastPosition = last // keep where the whole step started from
+			}
+		}
+	}
+	return nil
+}
+
+// nodeSpringJSON saves a spring between two nodes of a mesh by their indexes.
+type nodeSpringJSON struct {
+	From       int     `json:"from"`
+	To         int     `json:"to"`
+	Length     float32 `json:"length"`
+	Stiffness  float32 `json:"stiffness"`
+	Damping    float32 `json:"damping"`
+	BreakRatio float32 `json:"breakRatio,omitempty"`
+}
+
+func marshalNodeSprings(nodes []*Circle, springs []*Spring) []nodeSpringJSON {
+	index := make(map[*Circle]int, len(nodes))
+	for i, n := range nodes {
+		index[n] = i
+	}
+	saved := make([]nodeSpringJSON, len(springs))
+	for i, sp := range springs {
+		from, to := sp.Ends()
+		saved[i] = nodeSpringJSON{
+			From:       index[from],
+			To:         index[to],
+			Length:     sp.Length,
+			Stiffness:  sp.Stiffness,
+			Damping:    sp.Damping,
+			BreakRatio: sp.BreakRatio,
+		}
+	}
+	return saved
+}
+
+// unmarshalNodeSprings rebuilds saved springs between nodes, skipping any whose ends are missing.
+func unmarshalNodeSprings(nodes []*Circle, saved []nodeSpringJSON, color color.Color) []*Spring {
+	var springs []*Spring
+	for _, sp := range saved {
+		if sp.From < 0 || sp.From >= len(nodes) || sp.To < 0 || sp.To >= len(nodes) {
+			continue
+		}
+		spring := NewSpring(nodes[sp.From], nodes[sp.To], sp.Stiffness, 1, color)
+		spring.Length = sp.Length
+		spring.Damping = sp.Damping
+		spring.BreakRatio = sp.BreakRatio
+		springs = append(springs, spring)
+	}
+	return springs
+}
+
+// springJSON saves a spring that's an object of its own. Its ends are circles elsewhere in the
+// World's Objects, saved by their indexes there.
+type springJSON struct {
+	Type       string  `json:"type"`
+	From       int     `json:"from"`
+	To         int     `json:"to"`
+	Length     float32 `json:"length"`
+	Stiffness  float32 `json:"stiffness"`
+	Damping    float32 `json:"damping"`
+	BreakRatio float32 `json:"breakRatio,omitempty"`
+	Thickness  float32 `json:"thickness"`
+	ColorR     uint8   `json:"R"`
+	ColorG     uint8   `json:"G"`
+	ColorB     uint8   `json:"B"`
+	ColorA     uint8   `json:"A"`
+}
+
+// marshalSpring saves s with its ends looked up in objects, which has to hold them both.
+func marshalSpring(s *Spring, objects []Object) (springJSON, error) {
+	from := slices.Index(objects, Object(s.c1))
+	to := slices.Index(objects, Object(s.c2))
+	if from < 0 || to < 0 {
+		return springJSON{}, fmt.Errorf("can't save a spring whose ends aren't circles in the world")
+	}
+	c := s.Color.(color.RGBA)
+	return springJSON{
+		Type:       "Spring",
+		From:       from,
+		To:         to,
+		Length:     s.Length,
+		Stiffness:  s.Stiffness,
+		Damping:    s.Damping,
+		BreakRatio: s.BreakRatio,
+		Thickness:  s.Thickness,
+		ColorR:     c.R,
+		ColorG:     c.G,
+		ColorB:     c.B,
+		ColorA:     c.A,
+	}, nil
+}
+
+// unmarshalSpring rebuilds a saved spring between the circles in objects it was joining.
+func unmarshalSpring(data []byte, objects []Object) (*Spring, error) {
+	var aux springJSON
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return nil, err
+	}
+	end := func(i int) (*Circle, error) {
+		if i >= 0 && i < len(objects) {
+			if c, ok := objects[i].(*Circle); ok {
+				return c, nil
+			}
+		}
+		return nil, fmt.Errorf("spring end %d isn't a circle", i)
+	}
+	from, err := end(aux.From)
+	if err != nil {
+		return nil, err
+	}
+	to, err := end(aux.To)
+	if err != nil {
+		return nil, err
+	}
+	s := NewSpring(from, to, aux.Stiffness, aux.Thickness, color.RGBA{R: aux.ColorR, G: aux.ColorG, B: aux.ColorB, A: aux.ColorA})
+	s.Length = aux.Length
+	s.Damping = aux.Damping
+	s.BreakRatio = aux.BreakRatio
+	return s, nil
+}
@@ -0,0 +1,74 @@
+package world
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestSpringDampingSettles(t *testing.T) {
+	// swing returns the largest stretch over the last second of five
+	swing := func(damping float32) float32 {
+		w := New()
+		a := NewCircle(0, 0, 1, color.RGBA{}, Vector{})
+		b := NewCircle(50, 0, 1, color.RGBA{}, Vector{})
+		s := NewSpring(a, b, 100*a.Mass, 1, color.RGBA{})
+		s.Damping = damping * a.Mass
+		b.X = 70 // pull it 20 past its rest length
+		w.Add(a, b, s)
+		var largest float32
+		for i := range 300 {
+			if err := w.Step(w.TimeStep); err != nil {
+				t.Fatal(err)
+			}
+			if i >= 240 {
+				largest = max(largest, abs(b.X-a.X-s.Length))
+			}
+		}
+		return largest
+	}
+
+	if undamped := swing(0); undamped < 5 {
+		t.Errorf("undamped spring should keep oscillating, largest stretch %.2f", undamped)
+	}
+	if damped := swing(10); damped > 0.1 {
+		t.Errorf("damped spring should have settled at its rest length, largest stretch %.2f", damped)
+	}
+}
+
+func TestSpringBreaks(t *testing.T) {
+	w := New()
+	a := NewCircle(0, 0, 1, color.RGBA{}, Vector{X: -300})
+	b := NewCircle(50, 0, 1, color.RGBA{}, Vector{X: 300})
+	s := NewSpring(a, b, a.Mass, 1, color.RGBA{})
+	s.BreakRatio = 2
+	w.Add(a, b, s)
+
+	var snapped []*Spring
+	w.Events.SpringBreak.Subscribe(func(s *Spring) {
+		snapped = append(snapped, s)
+	})
+	for range 60 {
+		if err := w.Step(w.TimeStep); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(snapped) != 1 || snapped[0] != s {
+		t.Fatalf("expected the spring to break once, got %v", snapped)
+	}
+	for _, o := range w.Objects {
+		if o == Object(s) {
+			t.Error("broken spring is still in the world")
+		}
+	}
+
+	cube := NewCube(0, 0, 20, 20, color.RGBA{}, Vector{})
+	cube.Springs[0].BreakRatio = 1.5
+	cube.Points[1].Velocity = Vector{Y: 3000}
+	w.Add(cube)
+	if err := w.Step(w.TimeStep); err != nil {
+		t.Fatal(err)
+	}
+	if len(cube.Springs) != 3 || len(snapped) != 2 {
+		t.Errorf("cube should have lost one spring: %d springs left, %d breaks", len(cube.Springs), len(snapped))
+	}
+}
@@ -1,4 +1,4 @@
-package levels
+package world
 
 import "math"
 
@@ -64,6 +64,23 @@ func (v Vector) Scale(scalar float32) Vector {
 	return Vector{X: v.X * scalar, Y: v.Y * scalar}
 }
 
+// Cross returns the z component of the 3D cross product of v and u.
+func (v Vector) Cross(u Vector) float32 {
+	return v.X*u.Y - v.Y*u.X
+}
+
+// Perp returns v rotated 90 degrees, which scaled by an angular velocity gives the linear velocity
+// of a point at offset v from the center of rotation.
+func (v Vector) Perp() Vector {
+	return Vector{X: -v.Y, Y: v.X}
+}
+
+// Rotate returns v turned by angle radians.
+func (v Vector) Rotate(angle float32) Vector {
+	sin, cos := math.Sincos(float64(angle))
+	return Vector{X: v.X*float32(cos) - v.Y*float32(sin), Y: v.X*float32(sin) + v.Y*float32(cos)}
+}
+
 func (v Vector) Length() float32 {
 	return float32(math.Sqrt(float64(v.X*v.X + v.Y*v.Y)))
 }
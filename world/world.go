@@ -0,0 +1,341 @@
+package world
+
+import (
+	"slices"
+)
+
+// GravityConstant is the downward acceleration, in pixels per second squared, applied to every
+// rigid body while gravity is enabled.
+var GravityConstant = float32(9.8 * 2 * 60)
+
+const (
+	// DefaultTimeStep is the fixed simulation step used by New.
+	DefaultTimeStep = 1 / float32(60)
+	// maxFrameTime caps how much real time one Advance call may simulate so a long hitch can't
+	// snowball into ever more steps per frame.
+	maxFrameTime = 0.25
+	// defaultCellSize suits the few-dozen-pixel circles the levels spawn.
+	defaultCellSize = 64
+)
+
+// Object is anything that lives in a World and advances with it.
+type Object interface {
+	Update(delta float32) error
+}
+
+// Composite is implemented by objects built from other objects, like the corners of a Cube. The
+// World collides the parts, and pulls them with gravity, as if they were objects of their own;
+// the composite only moves them in its Update.
+type Composite interface {
+	Object
+	Parts() []Object
+}
+
+// World owns the simulated objects and steps them without any dependency on a renderer, so
+// scenes can be run headless in tests and tools.
+type World struct {
+	Objects []Object
+	Gravity bool
+	// NBody replaces the uniform pull of Gravity with every circle attracting every other one,
+	// at NBodyConstant times the other's mass over the square of the distance between them,
+	// softened by NBodySoftening pixels.
+	NBody          bool
+	NBodyConstant  float32
+	NBodySoftening float32
+	// TimeStep is the fixed simulation step in seconds.
+	TimeStep float32
+	// Substeps splits every TimeStep into this many smaller integration steps.
+	Substeps int
+	// Broadphase picks the candidate pairs for CheckCollisions. A nil Broadphase tests every pair.
+	Broadphase     Broadphase `json:"-"`
+	CollisionCount int        `json:"-"`
+	// VelocityIterations and PositionIterations are how many passes the contact solver makes
+	// over all contacts every step. More passes make stacks and piles stiffer.
+	VelocityIterations int
+	PositionIterations int
+	// Baumgarte is the fraction of the remaining overlap each position pass removes, and Slop the
+	// overlap in pixels left alone so resting contacts don't jitter.
+	Baumgarte float32
+	Slop      float32
+	// WarmStarting seeds every step's solve with the impulses contacts ended the last one with.
+	WarmStarting bool
+	// Joints are solved alongside the contacts. They aren't saved with the objects.
+	Joints []Joint `json:"-"`
+	// Events publishes contacts and breaking springs to whoever subscribes.
+	Events Events `json:"-"`
+	// AllowSleep lets bodies that have been at rest for SleepTime seconds fall asleep, together with
+	// everything they touch or are joined to. Sleeping bodies aren't moved or collision checked
+	// until something awake runs into them. A body is at rest while its speed stays under
+	// SleepVelocity, in pixels per second, and its spin under SleepAngularVelocity, in radians per
+	// second.
+	AllowSleep           bool
+	SleepTime            float32
+	SleepVelocity        float32
+	SleepAngularVelocity float32
+
+	accumulator float32
+	impulses    map[[2]Object][]cachedImpulse
+	// touching are the pairs of bodies in contact in the last step, and islands the bodies asleep,
+	// each mapped to everything that fell asleep with it.
+	touching [][2]*Body
+	islands  map[*Body]island
+	// contacts are the contacts published in the last step.
+	contacts []ContactEvent
+}
+
+func New() *World {
+	return &World{
+		NBodyConstant:        DefaultNBodyConstant,
+		NBodySoftening:       DefaultNBodySoftening,
+		TimeStep:             DefaultTimeStep,
+		Substeps:             1,
+		Broadphase:           NewSpatialHash(defaultCellSize),
+		VelocityIterations:   DefaultVelocityIterations,
+		PositionIterations:   DefaultPositionIterations,
+		Baumgarte:            DefaultBaumgarte,
+		Slop:                 DefaultSlop,
+		WarmStarting:         true,
+		AllowSleep:           true,
+		SleepTime:            DefaultSleepTime,
+		SleepVelocity:        DefaultSleepVelocity,
+		SleepAngularVelocity: DefaultSleepAngularVelocity,
+	}
+}
+
+func (w *World) Add(objects ...Object) {
+	w.Objects = append(w.Objects, objects...)
+}
+
+// AddJoint adds joints between bodies already in the world.
+func (w *World) AddJoint(joints ...Joint) {
+	w.Joints = append(w.Joints, joints...)
+}
+
+// Advance feeds elapsed real time into the world and runs as many fixed TimeSteps as have
+// accumulated. Leftover time carries over to the next call, so the simulation runs at the same
+// speed no matter how often Advance is called.
+func (w *World) Advance(elapsed float32) error {
+	if w.TimeStep <= 0 {
+		w.TimeStep = DefaultTimeStep
+	}
+	w.accumulator += min(elapsed, maxFrameTime)
+	for w.accumulator >= w.TimeStep {
+		if err := w.Step(w.TimeStep); err != nil {
+			return err
+		}
+		w.accumulator -= w.TimeStep
+	}
+	return nil
+}
+
+// Step advances the world by exactly delta seconds, split into Substeps: objects move, gravity,
+// force fields and fluids are applied, liquids flow, collisions are resolved and bodies that have
+// come to rest fall asleep. Sensors look for the bodies overlapping them at the end of the step.
+func (w *World) Step(delta float32) error {
+	substeps := max(w.Substeps, 1)
+	h := delta / float32(substeps)
+	for range substeps {
+		for _, o := range w.Objects {
+			if Asleep(o) {
+				continue
+			}
+			if err := o.Update(h); err != nil {
+				return err
+			}
+		}
+		w.removeBrokenSprings()
+		w.wakeInMovingContainers()
+
+		w.ApplyGravity(h)
+		w.ApplyForceFields(h)
+		w.applyFluids(h)
+		w.stepLiquids(h)
+		w.CheckCollisions()
+		w.updateSleep(h)
+	}
+	w.updateSensors()
+	return nil
+}
+
+// removeBrokenSprings drops the springs that snapped during the step, whether they're objects of
+// their own or part of a Cube, SoftBody or Cloth.
+func (w *World) removeBrokenSprings() {
+	var broken []*Spring
+	isBroken := func(s *Spring) bool {
+		if s.Broken {
+			broken = append(broken, s)
+		}
+		return s.Broken
+	}
+	w.Objects = slices.DeleteFunc(w.Objects, func(o Object) bool {
+		switch o := o.(type) {
+		case *Spring:
+			return isBroken(o)
+		case *Cube:
+			o.Springs = slices.DeleteFunc(o.Springs, isBroken)
+		case *SoftBody:
+			o.Springs = slices.DeleteFunc(o.Springs, isBroken)
+		case *Cloth:
+			o.Springs = slices.DeleteFunc(o.Springs, isBroken)
+		}
+		return false
+	})
+	for _, s := range broken {
+		w.Events.SpringBreak.publish(s)
+	}
+}
+
+// colliders returns the objects that take part in collisions, with every Composite replaced by
+// its parts. Sensors only watch, force fields and fluids only push and liquids are stepped on
+// their own, so they're left out.
+func (w *World) colliders() []Object {
+	objects := make([]Object, 0, len(w.Objects))
+	for _, o := range w.Objects {
+		switch o := o.(type) {
+		case Composite:
+			objects = append(objects, o.Parts()...)
+		case *Sensor, *ForceField, *Fluid, *Liquid:
+		default:
+			objects = append(objects, o)
+		}
+	}
+	return objects
+}
+
+// ApplyGravity pulls every awake body down, or when NBody is set, every circle towards the others.
+// Bodies without mass, static or kinematic, aren't pulled.
+func (w *World) ApplyGravity(delta float32) {
+	if w.NBody {
+		w.applyNBodyGravity(delta)
+		return
+	}
+	if !w.Gravity {
+		return
+	}
+	for _, o := range w.colliders() {
+		if r, ok := o.(Rigid); ok && r.RigidBody().Mass != 0 && !r.RigidBody().Sleeping {
+			r.RigidBody().Velocity.Y += GravityConstant * delta
+		}
+	}
+}
+
+// CheckCollisions finds every contact in the world and then solves them all together with the
+// joints: a few velocity passes of sequential impulses, followed by position passes that push
+// apart whatever still overlaps and pull joints back together. Solving all contacts at once,
+// rather than pair by pair, lets the push from the floor travel up through a stack.
+func (w *World) CheckCollisions() {
+	var broadphase Broadphase = BruteForce{}
+	if w.Broadphase != nil {
+		broadphase = w.Broadphase
+	}
+
+	// sweep bullets before anything else, since that moves them back to where they hit something
+	objects := w.colliders()
+	pairs := broadphasePairs(broadphase, objects)
+	swept := sweepBullets(objects, pairs)
+
+	// only pairs with an awake body in them are checked. Whatever an awake body touches wakes up
+	// with the rest of its island, so the pairs that woke are checked too.
+	manifolds := make([]Manifold, len(pairs))
+	checked := make([]bool, len(pairs))
+	for range 2 {
+		for i, pair := range pairs {
+			if checked[i] || (resting(pair.A) && resting(pair.B)) || !canCollide(pair.A, pair.B) {
+				continue
+			}
+			checked[i] = true
+			if m, ok := swept[pair]; ok {
+				manifolds[i] = m
+			} else {
+				manifolds[i] = CheckCollision(pair.A, pair.B)
+			}
+			if manifolds[i].Hit() {
+				w.Wake(pair.A)
+				w.Wake(pair.B)
+			}
+		}
+	}
+
+	var contacts []*solverContact
+	var keys [][2]Object
+	var contactManifolds []Manifold
+	w.touching = w.touching[:0]
+	for i, pair := range pairs {
+		o1, o2 := pair.A, pair.B
+		m := manifolds[i]
+		if !m.Hit() {
+			continue
+		}
+		w.CollisionCount++
+		// put the static side first; CheckCollision already points the normal away from it
+		switch o2.(type) {
+		case *CubeBoundary, *Boundary:
+			o1, o2 = o2, o1
+		}
+
+		var sc *solverContact
+		switch r1 := o1.(type) {
+		case *CubeBoundary:
+			if r, ok := o2.(Rigid); ok {
+				b := r.RigidBody()
+				sc = newSolverContact(nil, b, m, b.Restitution, b.Friction)
+				if r1.Moving() {
+					sc.moveWith(r1.VelocityAt)
+				}
+			}
+		case *Boundary:
+			if r, ok := o2.(Rigid); ok {
+				b := r.RigidBody()
+				sc = newSolverContact(nil, b, m, b.Restitution, b.Friction)
+			}
+		case Rigid:
+			if r2, ok := o2.(Rigid); ok {
+				a, b := r1.RigidBody(), r2.RigidBody()
+				sc = newSolverContact(a, b, m, mixRestitution(a.Restitution, b.Restitution), mixFriction(a.Friction, b.Friction))
+				w.touching = append(w.touching, [2]*Body{a, b})
+			}
+		}
+		if sc != nil {
+			contacts = append(contacts, sc)
+			keys = append(keys, [2]Object{o1, o2})
+			contactManifolds = append(contactManifolds, m)
+		}
+	}
+
+	for _, j := range w.Joints {
+		j.prepare(w.WarmStarting)
+	}
+	if w.WarmStarting {
+		for i, sc := range contacts {
+			sc.warmStart(w.impulses[keys[i]])
+		}
+	}
+	for range max(w.VelocityIterations, 1) {
+		for _, j := range w.Joints {
+			j.solveVelocity()
+		}
+		for _, sc := range contacts {
+			sc.solveVelocity()
+		}
+	}
+	for range w.PositionIterations {
+		for _, j := range w.Joints {
+			j.solvePosition()
+		}
+		for _, sc := range contacts {
+			sc.solvePosition(w.Baumgarte, w.Slop)
+		}
+	}
+
+	w.impulses = make(map[[2]Object][]cachedImpulse, len(contacts))
+	events := make([]ContactEvent, len(contacts))
+	for i, sc := range contacts {
+		w.impulses[keys[i]] = sc.impulses()
+		deepest := contactManifolds[i].Deepest()
+		events[i] = ContactEvent{A: keys[i][0], B: keys[i][1], Normal: deepest.Normal, Point: deepest.Point}
+		for _, p := range sc.points {
+			events[i].Impulse += p.normalImpulse
+		}
+	}
+	w.publishContacts(events, objects)
+}
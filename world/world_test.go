@@ -0,0 +1,164 @@
+package world
+
+import (
+	"fmt"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStepHeadless(t *testing.T) {
+	w := New()
+	floor := NewBoundaryLine(Point{X: 0, Y: 100}, Point{X: 200, Y: 100}, 2, color.RGBA{})
+	ceiling := NewBoundaryLine(Point{X: 200, Y: 0}, Point{X: 0, Y: 0}, 2, color.RGBA{})
+	ball := NewCircle(100, 50, 10, color.RGBA{}, Vector{X: 0, Y: 180})
+	w.Add(floor, ceiling, ball)
+
+	for i := range 5000 {
+		if err := w.Step(1 / float32(60)); err != nil {
+			t.Fatalf("step %d: %v", i, err)
+		}
+		if ball.Y < 0 || ball.Y > 100 {
+			t.Fatalf("step %d: ball escaped, y = %.2f", i, ball.Y)
+		}
+	}
+	if w.CollisionCount == 0 {
+		t.Errorf("expected the ball to hit the walls")
+	}
+}
+
+func TestAdvanceUsesFixedTimestep(t *testing.T) {
+	for _, substeps := range []int{1, 4} {
+		w := New()
+		w.Substeps = substeps
+		ball := NewCircle(0, 0, 1, color.RGBA{}, Vector{X: 60, Y: 0})
+		w.Add(ball)
+
+		// half a step is banked without moving anything
+		if err := w.Advance(w.TimeStep / 2); err != nil {
+			t.Fatal(err)
+		}
+		if ball.X != 0 {
+			t.Fatalf("substeps %d: ball moved before a full step accumulated: x = %.3f", substeps, ball.X)
+		}
+
+		// uneven frame times still add up to one second of travel, plus a hair to absorb rounding
+		for _, frame := range []float32{0.1, 0.2, 0.15, 0.25, 0.2, 0.1 - w.TimeStep/2 + 0.001} {
+			if err := w.Advance(frame); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if diff := ball.X - 60; diff < -0.01 || diff > 0.01 {
+			t.Errorf("substeps %d: got x = %.3f after one second, want 60", substeps, ball.X)
+		}
+	}
+}
+
+func TestSaveLoadState(t *testing.T) {
+	w := New()
+	w.Add(
+		NewBoundaryLine(Point{X: 0, Y: 100}, Point{X: 200, Y: 100}, 2, color.RGBA{R: 255, A: 255}),
+		NewCircle(10, 20, 5, color.RGBA{G: 255, A: 255}, Vector{X: 1, Y: 2}),
+	)
+	filename := filepath.Join(t.TempDir(), "save.json")
+	if err := w.SaveState(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := New()
+	if err := loaded.LoadState(filename); err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Objects) != 2 {
+		t.Fatalf("got %d objects, want 2", len(loaded.Objects))
+	}
+	c, ok := loaded.Objects[1].(*Circle)
+	if !ok {
+		t.Fatalf("got %T, want *Circle", loaded.Objects[1])
+	}
+	if c.Point != (Point{X: 10, Y: 20}) || c.Radius != 5 || c.Velocity != (Vector{X: 1, Y: 2}) {
+		t.Errorf("circle did not round trip: %+v", c)
+	}
+}
+
+func TestStaticCircleSaveLoad(t *testing.T) {
+	w := New()
+	peg := NewCircle(10, 20, 5, color.RGBA{A: 255}, Vector{})
+	peg.Mass, peg.Inertia = 0, 0
+	w.Add(peg)
+	filename := filepath.Join(t.TempDir(), "save.json")
+	if err := w.SaveState(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := New()
+	if err := loaded.LoadState(filename); err != nil {
+		t.Fatal(err)
+	}
+	if c := loaded.Objects[0].(*Circle); c.Mass != 0 || c.Inertia != 0 {
+		t.Errorf("static circle came back with mass %v and inertia %v", c.Mass, c.Inertia)
+	}
+
+	// saves from before mass was saved get the default
+	if err := os.WriteFile(filename, []byte(`{"Objects":[{"type":"Circle","radius":5}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := loaded.LoadState(filename); err != nil {
+		t.Fatal(err)
+	}
+	if c := loaded.Objects[0].(*Circle); c.Mass != peg.defaultMass() || c.Inertia == 0 {
+		t.Errorf("circle without a saved mass got mass %v and inertia %v", c.Mass, c.Inertia)
+	}
+}
+
+func TestSaveLoadEveryType(t *testing.T) {
+	w := New()
+	red := color.RGBA{R: 255, A: 255}
+	liquid := NewLiquid(red)
+	liquid.Pour(0, 0, 20, 20, Vector{})
+	// the spring comes before the circles at its ends
+	a, b := NewCircle(200, 20, 5, red, Vector{}), NewCircle(230, 20, 5, red, Vector{})
+	spring := NewSpring(a, b, 10, 2, red)
+	spring.BreakRatio = 3
+	w.Add(spring, a, b)
+	w.Add(
+		NewBoundaryLine(Point{X: 0, Y: 100}, Point{X: 200, Y: 100}, 2, red),
+		NewCubeBoundary(0, 0, 300, 300, 2, red),
+		// a cube early on, since an object that didn't load used to drop everything after it
+		NewCube(10, 10, 20, 20, red, Vector{}),
+		NewCircle(10, 20, 5, red, Vector{X: 1, Y: 2}),
+		NewBox(30, 30, 10, 10, red, Vector{}),
+		NewRegularPolygon(50, 50, 10, 5, red, Vector{}),
+		NewSoftBody(100, 100, 20, 8, false, red, Vector{}),
+		NewCloth(150, 10, 3, 3, 10, red),
+		NewRectSensor(0, 0, 50, 50, red),
+		NewAttractor(Point{X: 100, Y: 100}, 50, 1000, red),
+		NewWater(0, 200, 100, 50, red),
+		liquid,
+		NewKinematic(NewBox(200, 200, 40, 10, red, Vector{}), Spin{Pivot: Point{X: 220, Y: 205}, AngularVelocity: 1}),
+	)
+	filename := filepath.Join(t.TempDir(), "save.json")
+	if err := w.SaveState(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := New()
+	if err := loaded.LoadState(filename); err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Objects) != len(w.Objects) {
+		t.Fatalf("got %d objects, want %d", len(loaded.Objects), len(w.Objects))
+	}
+	for i, o := range w.Objects {
+		if got, want := fmt.Sprintf("%T", loaded.Objects[i]), fmt.Sprintf("%T", o); got != want {
+			t.Errorf("object %d: got %s, want %s", i, got, want)
+		}
+	}
+	if s, ok := loaded.Objects[0].(*Spring); ok {
+		from, to := s.Ends()
+		if from != loaded.Objects[1] || to != loaded.Objects[2] || s.Length != spring.Length || s.BreakRatio != spring.BreakRatio || s.Thickness != spring.Thickness {
+			t.Errorf("spring did not round trip: %+v", *s)
+		}
+	}
+}